@@ -0,0 +1,128 @@
+// Package banburismus implements the method devised by Alan Turing to find the rotor in the fast position of the
+// Kriegsmarine enigma, from messages whose indicators share the first two letters.
+//
+// Two messages encoded with the same slow and middle rotor positions are "in depth" when slid over each other by
+// the distance between their fast rotor positions: the same letters are encoded by the same machine state, and
+// repeated letters show up much more often than in random text. The evidence of each alignment is measured in
+// decibans, as in Turing's "Treatise on the Enigma". The best alignments give the distances between the fast
+// positions of the indicator letters, which are linked together in chains ("scritching"), and the chains are tested
+// against the turnover notches of each rotor to find the candidates for the fast rotor.
+package banburismus
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/ibraimgm/enigma/text"
+)
+
+// Kappa is the probability of two letters of German naval plaintext in depth being equal, as used by Turing.
+const Kappa = 1.0 / 17.0
+
+var (
+	// RepeatScore is the evidence of a repeated letter in an alignment, in decibans (about +1.8).
+	RepeatScore = 10 * math.Log10(Kappa*26)
+	// NonRepeatScore is the evidence of a letter that is not repeated in an alignment, in decibans (about -0.09).
+	NonRepeatScore = 10 * math.Log10((1-Kappa)/(1-1.0/26))
+)
+
+// Decibans returns the evidence that an alignment is in depth, from the number of repeated letters and the number
+// of letters in the overlap.
+func Decibans(repeats, overlap int) float64 {
+	return float64(repeats)*RepeatScore + float64(overlap-repeats)*NonRepeatScore
+}
+
+// Message is an intercepted message, with its indicator (the letters that give the position of the rotors, after
+// the bigram tables are stripped) and its text.
+type Message struct {
+	Indicator string
+	Text      string
+}
+
+// Alignment is the comparison of two messages, where the second one starts Offset keypresses after the first one.
+type Alignment struct {
+	Offset  int
+	Overlap int
+	Repeats int
+	Score   float64
+}
+
+// Compare slides the text b over the text a, with b starting offset letters after a, and counts the repeats.
+// Only the letters are considered, and case does not matter.
+func Compare(a, b string, offset int) Alignment {
+	x, y := text.Letters(a), text.Letters(b)
+	al := Alignment{Offset: offset}
+
+	for i := 0; i < len(y) && i+offset < len(x); i++ {
+		if i+offset < 0 {
+			continue
+		}
+
+		al.Overlap++
+		if x[i+offset] == y[i] {
+			al.Repeats++
+		}
+	}
+
+	al.Score = Decibans(al.Repeats, al.Overlap)
+	return al
+}
+
+// Align compares the messages with b starting from 1 to 25 keypresses after a, with the best alignments first.
+func Align(a, b string) []Alignment {
+	alignments := make([]Alignment, 0, 25)
+
+	for offset := 1; offset < 26; offset++ {
+		alignments = append(alignments, Compare(a, b, offset))
+	}
+
+	sort.SliceStable(alignments, func(i, j int) bool { return alignments[i].Score > alignments[j].Score })
+	return alignments
+}
+
+// Evidence is the distance between the fast rotor positions of two indicator letters: the position of To is
+// Distance steps after the position of From, so the message of To starts Distance keypresses after the message of
+// From. Score is the evidence of the alignment, in decibans.
+type Evidence struct {
+	From     byte
+	To       byte
+	Distance int
+	Score    float64
+}
+
+// Evidences compares every pair of messages whose indicators share the first two letters (and differ in the third
+// one), and returns the best alignment of each pair that scores at least threshold decibans, with the best first.
+func Evidences(messages []Message, threshold float64) ([]Evidence, error) {
+	for _, m := range messages {
+		if len(text.Letters(m.Indicator)) != 3 {
+			return nil, errors.New("invalid indicator '" + m.Indicator + "': should have 3 letters")
+		}
+	}
+
+	result := []Evidence{}
+
+	for i := range messages {
+		for j := i + 1; j < len(messages); j++ {
+			a, b := text.Letters(messages[i].Indicator), text.Letters(messages[j].Indicator)
+			if a[0] != b[0] || a[1] != b[1] || a[2] == b[2] {
+				continue
+			}
+
+			forward := Align(messages[i].Text, messages[j].Text)[0]
+			backward := Align(messages[j].Text, messages[i].Text)[0]
+
+			e := Evidence{From: a[2], To: b[2], Distance: forward.Offset, Score: forward.Score}
+			if backward.Score > forward.Score {
+				e = Evidence{From: b[2], To: a[2], Distance: backward.Offset, Score: backward.Score}
+			}
+
+			if e.Score >= threshold {
+				result = append(result, e)
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Score > result[j].Score })
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package banburismus_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/banburismus"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/ibraimgm/enigma/text"
+	"github.com/stretchr/testify/assert"
+)
+
+// german returns n letters of German text, starting at the specified letter
+func german(t *testing.T, start, n int) string {
+	corpus, err := os.ReadFile("../scoring/corpus/german.txt")
+	assert.NoError(t, err)
+
+	return text.Conventions["heer"].Prepare(string(corpus))[start : start+n]
+}
+
+// messages encodes German texts with the same slow and middle positions, and the fast positions of windows. The
+// indicators use the letters of indicators as their third letter, to simulate the encoded indicators.
+func messages(t *testing.T, windows, indicators string) []banburismus.Message {
+	result := []banburismus.Message{}
+
+	for i := range windows {
+		e, err := enigma.WithSettings(enigma.Settings{
+			Reflector: "B",
+			Slow:      "IV",
+			Middle:    "II",
+			Fast:      "III",
+			Window:    "KD" + windows[i:i+1],
+			Plugs:     "AZBYCXDW",
+		})
+		assert.NoError(t, err)
+
+		cipher := e.EncodeMessage(german(t, i*700, 600), 0)
+		result = append(result, banburismus.Message{Indicator: "RP" + indicators[i:i+1], Text: cipher})
+	}
+
+	return result
+}
+
+func TestDecibans(t *testing.T) {
+	assert.InDelta(t, 1.8, banburismus.RepeatScore, 0.05)
+	assert.InDelta(t, -0.09, banburismus.NonRepeatScore, 0.005)
+	assert.InDelta(t, 2*banburismus.RepeatScore+8*banburismus.NonRepeatScore, banburismus.Decibans(2, 10), 1e-9)
+}
+
+func TestCompare(t *testing.T) {
+	al := banburismus.Compare("ABCDEF", "cdxf", 2)
+	assert.Equal(t, 2, al.Offset)
+	assert.Equal(t, 4, al.Overlap)
+	assert.Equal(t, 3, al.Repeats)
+	assert.InDelta(t, banburismus.Decibans(3, 4), al.Score, 1e-9)
+
+	al = banburismus.Compare("ABCDEF", "XYZ", 5)
+	assert.Equal(t, 1, al.Overlap)
+
+	assert.Len(t, banburismus.Align("ABCDEF", "XYZ"), 25)
+}
+
+func TestEvidences(t *testing.T) {
+	// G is 2 steps after E, and K is 6 steps after E
+	msgs := messages(t, "EGK", "QAX")
+	msgs = append(msgs, banburismus.Message{Indicator: "ZZQ", Text: msgs[0].Text})
+
+	evidences, err := banburismus.Evidences(msgs, 10)
+	assert.NoError(t, err)
+	assert.Len(t, evidences, 3)
+
+	for _, e := range evidences {
+		switch string([]byte{e.From, e.To}) {
+		case "QA":
+			assert.Equal(t, 2, e.Distance)
+		case "QX":
+			assert.Equal(t, 6, e.Distance)
+		case "AX":
+			assert.Equal(t, 4, e.Distance)
+		default:
+			assert.Fail(t, "unexpected evidence", "%c -> %c", e.From, e.To)
+		}
+	}
+
+	_, err = banburismus.Evidences([]banburismus.Message{{Indicator: "AB", Text: "X"}}, 10)
+	assert.EqualError(t, err, "invalid indicator 'AB': should have 3 letters")
+}
@@ -0,0 +1,251 @@
+package banburismus
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/ibraimgm/enigma/machine/parts"
+)
+
+// Chain is a set of indicator letters with known distances between their fast rotor positions.
+type Chain struct {
+	// Positions of the letters in the chain, relative to the first letter (from 0 to 25); -1 for letters that are
+	// not in the chain.
+	Positions [26]int
+	// Evidences used to build the chain.
+	Evidences []Evidence
+}
+
+// Letters returns the letters of the chain, in the order of their positions.
+func (c *Chain) Letters() []byte {
+	result := []byte{}
+
+	for x, p := range c.Positions {
+		if p >= 0 {
+			result = append(result, byte('A'+x))
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return c.Positions[result[i]-'A'] < c.Positions[result[j]-'A'] })
+	return result
+}
+
+// String returns the chain as in the Banburismus sheets, with a dot for each unknown position (ex: "Q.A..X").
+func (c *Chain) String() string {
+	slots := []byte(strings.Repeat(".", 26))
+	last := 0
+
+	for x, p := range c.Positions {
+		if p >= 0 {
+			slots[p] = byte('A' + x)
+			if p > last {
+				last = p
+			}
+		}
+	}
+
+	return string(slots[:last+1])
+}
+
+// Chains links the evidences into chains, starting with the best evidences. Evidences that contradict the chains
+// built so far are discarded, as are the ones that link two letters already in the same chain.
+func Chains(evidences []Evidence) []*Chain {
+	sorted := append([]Evidence(nil), evidences...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Score > sorted[j].Score })
+
+	// chain of each letter, and its position in the chain
+	var owner [26]*Chain
+	chains := []*Chain{}
+
+	for _, e := range sorted {
+		from, to := int(e.From-'A'), int(e.To-'A')
+		a, b := owner[from], owner[to]
+
+		switch {
+		case a == nil && b == nil:
+			c := &Chain{}
+			for i := range c.Positions {
+				c.Positions[i] = -1
+			}
+
+			c.Positions[from], c.Positions[to] = 0, e.Distance%26
+			owner[from], owner[to] = c, c
+			chains = append(chains, c)
+			c.Evidences = append(c.Evidences, e)
+
+		case a != nil && b == nil:
+			p := (a.Positions[from] + e.Distance) % 26
+			if !a.free(p) {
+				continue
+			}
+
+			a.Positions[to], owner[to] = p, a
+			a.Evidences = append(a.Evidences, e)
+
+		case a == nil && b != nil:
+			p := (b.Positions[to] - e.Distance%26 + 26) % 26
+			if !b.free(p) {
+				continue
+			}
+
+			b.Positions[from], owner[from] = p, b
+			b.Evidences = append(b.Evidences, e)
+
+		case a != b:
+			shift := (a.Positions[from] + e.Distance - b.Positions[to] + 52) % 26
+			if !a.canMerge(b, shift) {
+				continue
+			}
+
+			for x, p := range b.Positions {
+				if p >= 0 {
+					a.Positions[x], owner[x] = (p+shift)%26, a
+				}
+			}
+
+			a.Evidences = append(append(a.Evidences, b.Evidences...), e)
+			chains = remove(chains, b)
+		}
+	}
+
+	for _, c := range chains {
+		c.normalize()
+	}
+
+	return chains
+}
+
+// free reports whether no letter of the chain is at the position
+func (c *Chain) free(p int) bool {
+	for _, q := range c.Positions {
+		if q == p {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *Chain) canMerge(other *Chain, shift int) bool {
+	for _, p := range other.Positions {
+		if p >= 0 && !c.free((p+shift)%26) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// normalize moves the positions so the first letter of the chain is at zero, and the chain is as short as possible
+func (c *Chain) normalize() {
+	used := [26]bool{}
+	for _, p := range c.Positions {
+		if p >= 0 {
+			used[p] = true
+		}
+	}
+
+	// start after the largest gap
+	start, best := 0, -1
+	for p := 0; p < 26; p++ {
+		if !used[p] {
+			continue
+		}
+
+		gap := 0
+		for q := (p + 25) % 26; !used[q]; q = (q + 25) % 26 {
+			gap++
+		}
+
+		if gap > best {
+			start, best = p, gap
+		}
+	}
+
+	for x, p := range c.Positions {
+		if p >= 0 {
+			c.Positions[x] = (p - start + 26) % 26
+		}
+	}
+}
+
+func remove(chains []*Chain, c *Chain) []*Chain {
+	for i := range chains {
+		if chains[i] == c {
+			return append(chains[:i], chains[i+1:]...)
+		}
+	}
+
+	return chains
+}
+
+// Candidate is a rotor that can be in the fast position, with the window letter of each indicator letter of the
+// chain (0 for letters not in the chain).
+type Candidate struct {
+	Rotor   string
+	Windows [26]byte
+}
+
+// Candidates tests the chain against the turnover notches of every rotor of the model, in each of the 26 positions.
+// Since the messages of each evidence are in depth, the middle rotor cannot turn over between their fast positions;
+// the rotors and positions where this never happens are the candidates.
+func Candidates(chain *Chain, model enigma.Model) ([]Candidate, error) {
+	result := []Candidate{}
+
+	for _, id := range model.Rotors {
+		notches, err := notches(id)
+		if err != nil {
+			return nil, err
+		}
+
+		for shift := 0; shift < 26; shift++ {
+			if !chain.consistent(notches, shift) {
+				continue
+			}
+
+			c := Candidate{Rotor: id}
+			for x, p := range chain.Positions {
+				if p >= 0 {
+					c.Windows[x] = byte('A' + (p+shift)%26)
+				}
+			}
+
+			result = append(result, c)
+		}
+	}
+
+	return result, nil
+}
+
+// consistent reports whether no evidence of the chain crosses a notch, with the first letter of the chain at shift
+func (c *Chain) consistent(notches [26]bool, shift int) bool {
+	for _, e := range c.Evidences {
+		start := c.Positions[e.From-'A'] + shift
+
+		for k := 0; k < e.Distance; k++ {
+			if notches[(start+k)%26] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// notches returns the window positions where the rotor makes the next one turn over
+func notches(id string) ([26]bool, error) {
+	var result [26]bool
+
+	r, err := parts.GetRotor(id)
+	if err != nil {
+		return result, err
+	}
+
+	for x := range result {
+		r.SetWindow(rune('A' + x))
+		result[x] = r.IsNotched()
+	}
+
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package banburismus_test
+
+import (
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/banburismus"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChains(t *testing.T) {
+	evidences := []banburismus.Evidence{
+		{From: 'Q', To: 'A', Distance: 2, Score: 30},
+		{From: 'X', To: 'C', Distance: 3, Score: 25},
+		{From: 'A', To: 'X', Distance: 4, Score: 20},
+		{From: 'Q', To: 'C', Distance: 5, Score: 10}, // contradicts the others
+		{From: 'M', To: 'N', Distance: 1, Score: 15},
+	}
+
+	chains := banburismus.Chains(evidences)
+	assert.Len(t, chains, 2)
+	assert.Equal(t, "Q.A...X..C", chains[0].String())
+	assert.Equal(t, []byte("QAXC"), chains[0].Letters())
+	assert.Len(t, chains[0].Evidences, 3)
+	assert.Equal(t, "MN", chains[1].String())
+}
+
+func TestCandidates(t *testing.T) {
+	msgs := messages(t, "EGKNB", "QAXCL")
+	evidences, err := banburismus.Evidences(msgs, 10)
+	assert.NoError(t, err)
+
+	chains := banburismus.Chains(evidences)
+	assert.Len(t, chains, 1)
+	assert.Equal(t, "L..Q.A...X..C", chains[0].String())
+
+	candidates, err := banburismus.Candidates(chains[0], enigma.Models["M3"])
+	assert.NoError(t, err)
+	assert.True(t, len(candidates) < 8*26)
+
+	var expected banburismus.Candidate
+	expected.Rotor = "III"
+	for i, c := range []byte("EGKNB") {
+		expected.Windows["QAXCL"[i]-'A'] = c
+	}
+	assert.Contains(t, candidates, expected)
+
+	// with L in F, the evidence from L to C (12 steps) crosses the notch of rotor I in Q
+	for _, c := range candidates {
+		assert.False(t, c.Rotor == "I" && c.Windows['L'-'A'] == 'F')
+	}
+
+	_, err = banburismus.Candidates(chains[0], enigma.Model{Rotors: []string{"X"}})
+	assert.EqualError(t, err, "unrecognized rotor ID: 'X'")
+}
@@ -0,0 +1,208 @@
+// Package bombe simulates the Turing-Welchman bombe, used to find the rotor order and the rotor positions of an
+// enigma message from a crib (a piece of known plaintext).
+package bombe
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+)
+
+// Config specifies which scramblers the bombe should test.
+type Config struct {
+	// Reflector used in every scrambler. Empty means "B".
+	Reflector string
+	// Orders is the list of rotor orders (slow, middle and fast) to test.
+	Orders [][3]string
+	// Ring is the ring setting assumed by the bombe. Empty means "AAA".
+	// The stop windows are relative to this ring setting.
+	Ring string
+}
+
+// Stop is a rotor order and start position where the bombe stopped.
+type Stop struct {
+	Reflector string
+	Slow      string
+	Middle    string
+	Fast      string
+	// Window is the window setting before the first letter of the message is encoded.
+	Window string
+	// Steckers are the plug pairs implied by the stop (ex: "ABCD" means A-B and C-D). Letters that are steckered
+	// to themselves are not included.
+	Steckers string
+}
+
+// Orders returns every rotor order (without repetition) that can be made with the specified rotors.
+//
+// Deprecated: use enigma.RotorOrders.
+func Orders(rotors []string) [][3]string {
+	return enigma.RotorOrders(rotors)
+}
+
+// Run tests every rotor order and every start position against the menu, and returns the stops found.
+// The simulation uses the diagonal board, and a stop happens when the hypothesis of the steckered value of the
+// central letter is consistent with the menu.
+func Run(menu *Menu, config Config) ([]Stop, error) {
+	if len(menu.Edges) == 0 {
+		return nil, errors.New("the menu should have at least one edge")
+	}
+
+	if config.Reflector == "" {
+		config.Reflector = "B"
+	}
+
+	stops := []Stop{}
+
+	for _, order := range config.Orders {
+		s, err := enigma.NewPermutationCache(enigma.Settings{
+			Reflector: config.Reflector,
+			Slow:      order[0],
+			Middle:    order[1],
+			Fast:      order[2],
+			Ring:      config.Ring,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		stops = append(stops, run(s, menu)...)
+	}
+
+	return stops, nil
+}
+
+// link is an edge of the menu, with letters from 0 to 25
+type link struct {
+	other int
+	perm  *[26]byte
+}
+
+// run tests every start position of the scrambler (a machine without plugboard) against the menu
+func run(s *enigma.PermutationCache, menu *Menu) []Stop {
+	stops := []Stop{}
+	central := int(menu.Central() - 'A')
+	steps := make([]int, menu.maxPosition())
+	links := make([][]link, 26)
+	var state wires
+
+	for start := 0; start < enigma.Positions; start++ {
+		p := start
+		for i := range steps {
+			steps[i] = p
+			p = s.Next(p)
+		}
+
+		for i := range links {
+			links[i] = links[i][:0]
+		}
+
+		for _, e := range menu.Edges {
+			a, b := int(e.From-'A'), int(e.To-'A')
+			perm := s.Permutation(steps[e.Position-1])
+			links[a] = append(links[a], link{b, perm})
+			links[b] = append(links[b], link{a, perm})
+		}
+
+		state.propagate(links, central, 0)
+		if state.count(central) == 26 {
+			continue
+		}
+
+		candidates := []int{}
+		if state.count(central) == 1 {
+			candidates = append(candidates, 0)
+		} else {
+			for h := 1; h < 26; h++ {
+				if !state[central][h] {
+					candidates = append(candidates, h)
+				}
+			}
+		}
+
+		for _, h := range candidates {
+			var test wires
+			test.propagate(links, central, h)
+
+			if test.count(central) == 1 {
+				stops = append(stops, Stop{
+					Reflector: s.Settings().Reflector,
+					Slow:      s.Settings().Slow,
+					Middle:    s.Settings().Middle,
+					Fast:      s.Settings().Fast,
+					Window:    enigma.PositionToWindow(start),
+					Steckers:  test.steckers(),
+				})
+			}
+		}
+	}
+
+	return stops
+}
+
+// wires is the bombe electrical state: wires[x][y] is live when the hypothesis "x is steckered to y" is live
+type wires [26][26]bool
+
+// propagate resets the state and applies the voltage to a wire of a letter, following the menu links and the diagonal
+// board until no new wire is live
+func (w *wires) propagate(links [][]link, letter, wire int) {
+	*w = wires{}
+	stack := [][2]int{{letter, wire}}
+	w[letter][wire] = true
+
+	for len(stack) > 0 {
+		x, v := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+
+		// diagonal board
+		if !w[v][x] {
+			w[v][x] = true
+			stack = append(stack, [2]int{v, x})
+		}
+
+		for _, l := range links[x] {
+			u := int(l.perm[v])
+			if !w[l.other][u] {
+				w[l.other][u] = true
+				stack = append(stack, [2]int{l.other, u})
+			}
+		}
+	}
+}
+
+func (w *wires) count(letter int) int {
+	n := 0
+
+	for _, live := range w[letter] {
+		if live {
+			n++
+		}
+	}
+
+	return n
+}
+
+// steckers returns the pairs of every letter with a single live wire
+func (w *wires) steckers() string {
+	pairs := []string{}
+
+	for x := 0; x < 26; x++ {
+		if w.count(x) != 1 {
+			continue
+		}
+
+		for y := x + 1; y < 26; y++ {
+			if w[x][y] && w.count(y) == 1 {
+				pairs = append(pairs, string([]byte{byte('A' + x), byte('A' + y)}))
+			}
+		}
+	}
+
+	sort.Strings(pairs)
+	var result string
+	for _, p := range pairs {
+		result += p
+	}
+
+	return result
+}
@@ -0,0 +1,81 @@
+package bombe_test
+
+import (
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/bombe"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+const plain = "WETTERVORHERSAGEBISKAYAXWINDSTAERKEFUENFXSICHTWEITEGUT"
+
+func encode(t *testing.T, settings enigma.Settings, message string) string {
+	e, err := enigma.WithSettings(settings)
+	assert.NoError(t, err)
+
+	return e.EncodeMessage(message, 0)
+}
+
+func TestOrders(t *testing.T) {
+	orders := bombe.Orders([]string{"I", "II", "III"})
+	assert.Len(t, orders, 6)
+	assert.Contains(t, orders, [3]string{"III", "I", "II"})
+	assert.NotContains(t, orders, [3]string{"I", "I", "II"})
+
+	assert.Len(t, bombe.Orders([]string{"I", "II", "III", "IV", "V"}), 60)
+}
+
+func TestRunFindsKey(t *testing.T) {
+	settings := enigma.Settings{Reflector: "B", Slow: "II", Middle: "IV", Fast: "I", Window: "QWE", Plugs: "ATBLDFGJHMNWOPQYRZVX"}
+	cipher := encode(t, settings, plain)
+
+	menu, err := bombe.BuildMenu(cipher, "WETTERVORHERSAGEBISKAYA", 0)
+	assert.NoError(t, err)
+
+	stops, err := bombe.Run(menu, bombe.Config{Orders: [][3]string{{"II", "IV", "I"}}})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, stops)
+
+	found := false
+	for _, stop := range stops {
+		assert.Equal(t, "B", stop.Reflector)
+		assert.Equal(t, "II", stop.Slow)
+		assert.Equal(t, "IV", stop.Middle)
+		assert.Equal(t, "I", stop.Fast)
+
+		if stop.Window == "QWE" {
+			found = true
+
+			for i := 0; i < len(stop.Steckers); i += 2 {
+				pair := stop.Steckers[i : i+2]
+				reversed := string([]byte{pair[1], pair[0]})
+				assert.True(t, containsPair(settings.Plugs, pair) || containsPair(settings.Plugs, reversed), "unexpected stecker %s", pair)
+			}
+		}
+	}
+
+	assert.True(t, found, "the correct position was not found in %d stops", len(stops))
+}
+
+func containsPair(plugs, pair string) bool {
+	for i := 0; i+1 < len(plugs); i += 2 {
+		if plugs[i:i+2] == pair {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestRunError(t *testing.T) {
+	_, err := bombe.Run(&bombe.Menu{}, bombe.Config{})
+	assert.EqualError(t, err, "the menu should have at least one edge")
+
+	menu, _ := bombe.BuildMenu("BC", "AB", 0)
+	_, err = bombe.Run(menu, bombe.Config{Orders: [][3]string{{"I", "II", "X"}}})
+	assert.EqualError(t, err, "unrecognized rotor ID: 'X'")
+
+	_, err = bombe.Run(menu, bombe.Config{Reflector: "X", Orders: [][3]string{{"I", "II", "III"}}})
+	assert.EqualError(t, err, "unknown reflector: 'X'")
+}
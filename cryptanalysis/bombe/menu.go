@@ -0,0 +1,374 @@
+package bombe
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/crib"
+	"github.com/ibraimgm/enigma/text"
+)
+
+// Edge is a connection between a plain letter and a cipher letter of a crib.
+// Position is the number of the keypress that encoded the letter, starting with 1 for the first letter of the message,
+// in the same order the rotors step in an enigma.Enigma.
+type Edge struct {
+	From     byte
+	To       byte
+	Position int
+}
+
+// Menu is the set of connections between letters that a crib provides, used to configure the bombe.
+type Menu struct {
+	Edges []Edge
+}
+
+// BuildMenu builds the menu of a crib placed at the specified offset of the ciphertext (starting with zero).
+// Only the letters are considered, both in the ciphertext and in the crib; case is ignored.
+func BuildMenu(ciphertext, crib string, offset int) (*Menu, error) {
+	cipher := text.Letters(ciphertext)
+	plain := text.Letters(crib)
+
+	if len(plain) == 0 {
+		return nil, errors.New("the crib should have at least one letter")
+	}
+
+	if offset < 0 || offset+len(plain) > len(cipher) {
+		return nil, fmt.Errorf("the crib does not fit in the ciphertext at offset %d", offset)
+	}
+
+	m := &Menu{Edges: make([]Edge, len(plain))}
+
+	for i := range plain {
+		c := cipher[offset+i]
+
+		if plain[i] == c {
+			return nil, fmt.Errorf("the crib letter '%c' at offset %d encodes to itself", c, offset+i)
+		}
+
+		m.Edges[i] = Edge{From: plain[i], To: c, Position: offset + i + 1}
+	}
+
+	return m, nil
+}
+
+// Letters returns only the letters of s, in uppercase.
+//
+// Deprecated: use text.Letters.
+func Letters(s string) string {
+	return text.Letters(s)
+}
+
+// Letters returns the distinct letters of the menu, in alphabetical order.
+func (m *Menu) Letters() []byte {
+	seen := make(map[byte]bool)
+	letters := []byte{}
+
+	for _, e := range m.Edges {
+		for _, c := range []byte{e.From, e.To} {
+			if !seen[c] {
+				seen[c] = true
+				letters = append(letters, c)
+			}
+		}
+	}
+
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+	return letters
+}
+
+// Central returns the letter with most connections in the largest connected part of the menu (the first one, in
+// alphabetical order, in case of a tie). This is the letter connected to the bombe test register. It returns 0 for a
+// menu without edges.
+func (m *Menu) Central() byte {
+	components := m.Components()
+	if len(components) == 0 {
+		return 0
+	}
+
+	count := make(map[byte]int)
+
+	for _, e := range m.Edges {
+		count[e.From]++
+		count[e.To]++
+	}
+
+	var central byte
+	for _, c := range components[0] {
+		if count[c] > count[central] {
+			central = c
+		}
+	}
+
+	return central
+}
+
+// maxPosition returns the largest keypress number in the menu
+func (m *Menu) maxPosition() int {
+	max := 0
+
+	for _, e := range m.Edges {
+		if e.Position > max {
+			max = e.Position
+		}
+	}
+
+	return max
+}
+
+// Loop is a closure in the menu: a sequence of edges that starts and ends in the same letter.
+// Loops are what make the bombe reject wrong hypotheses, so the more loops a menu has, the fewer false stops.
+type Loop struct {
+	// Letters visited by the loop, where the last letter is connected back to the first one.
+	Letters []byte
+	// Edges of the loop, where Edges[i] connects Letters[i] to the next letter.
+	Edges []Edge
+}
+
+// Loops returns a set of independent loops of the menu (a cycle basis). Every other loop in the menu can be made by
+// combining these ones.
+func (m *Menu) Loops() []Loop {
+	parent, parentEdge, depth := m.spanningForest()
+	inTree := make(map[int]bool)
+
+	for _, e := range parentEdge {
+		if e != -1 {
+			inTree[e] = true
+		}
+	}
+
+	loops := []Loop{}
+	for i, e := range m.Edges {
+		if inTree[i] {
+			continue
+		}
+
+		// walk from both letters up to the common ancestor in the tree
+		a, b := int(e.From-'A'), int(e.To-'A')
+		left, right := []int{a}, []int{b}
+		leftEdges, rightEdges := []int{}, []int{}
+
+		for a != b {
+			if depth[a] >= depth[b] {
+				leftEdges = append(leftEdges, parentEdge[a])
+				a = parent[a]
+				left = append(left, a)
+			} else {
+				rightEdges = append(rightEdges, parentEdge[b])
+				b = parent[b]
+				right = append(right, b)
+			}
+		}
+
+		loop := Loop{}
+		for _, l := range left {
+			loop.Letters = append(loop.Letters, byte('A'+l))
+		}
+		for j := len(right) - 2; j >= 0; j-- {
+			loop.Letters = append(loop.Letters, byte('A'+right[j]))
+		}
+
+		for _, idx := range leftEdges {
+			loop.Edges = append(loop.Edges, m.Edges[idx])
+		}
+		for j := len(rightEdges) - 1; j >= 0; j-- {
+			loop.Edges = append(loop.Edges, m.Edges[rightEdges[j]])
+		}
+		loop.Edges = append(loop.Edges, e)
+
+		loops = append(loops, loop)
+	}
+
+	return loops
+}
+
+// spanningForest returns, for each letter, its parent letter, the index of the edge to the parent (-1 for roots and
+// letters not in the menu) and its depth in a breadth-first spanning forest of the menu
+func (m *Menu) spanningForest() (parent, parentEdge, depth [26]int) {
+	adjacent := m.adjacency()
+	visited := [26]bool{}
+
+	for i := range parentEdge {
+		parent[i] = i
+		parentEdge[i] = -1
+	}
+
+	for _, root := range m.Letters() {
+		r := int(root - 'A')
+		if visited[r] {
+			continue
+		}
+
+		visited[r] = true
+		queue := []int{r}
+
+		for len(queue) > 0 {
+			x := queue[0]
+			queue = queue[1:]
+
+			for _, idx := range adjacent[x] {
+				y := m.other(idx, x)
+				if !visited[y] {
+					visited[y] = true
+					parent[y] = x
+					parentEdge[y] = idx
+					depth[y] = depth[x] + 1
+					queue = append(queue, y)
+				}
+			}
+		}
+	}
+
+	return parent, parentEdge, depth
+}
+
+// adjacency returns the indexes of the edges of each letter
+func (m *Menu) adjacency() [26][]int {
+	var adjacent [26][]int
+
+	for i, e := range m.Edges {
+		adjacent[e.From-'A'] = append(adjacent[e.From-'A'], i)
+		adjacent[e.To-'A'] = append(adjacent[e.To-'A'], i)
+	}
+
+	return adjacent
+}
+
+// other returns the letter at the other end of the edge
+func (m *Menu) other(edge, letter int) int {
+	e := m.Edges[edge]
+
+	if int(e.From-'A') == letter {
+		return int(e.To - 'A')
+	}
+
+	return int(e.From - 'A')
+}
+
+// Components returns the letters of each connected part of the menu, with the largest parts (in number of edges)
+// first.
+func (m *Menu) Components() [][]byte {
+	parent, _, _ := m.spanningForest()
+	root := func(x int) int {
+		for parent[x] != x {
+			x = parent[x]
+		}
+		return x
+	}
+
+	groups := make(map[int][]byte)
+	edges := make(map[int]int)
+	for _, c := range m.Letters() {
+		r := root(int(c - 'A'))
+		groups[r] = append(groups[r], c)
+	}
+	for _, e := range m.Edges {
+		edges[root(int(e.From-'A'))]++
+	}
+
+	components := make([][]byte, 0, len(groups))
+	for _, g := range groups {
+		components = append(components, g)
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		ei, ej := edges[root(int(components[i][0]-'A'))], edges[root(int(components[j][0]-'A'))]
+		if ei != ej {
+			return ei > ej
+		}
+
+		return components[i][0] < components[j][0]
+	})
+
+	return components
+}
+
+// Score summarizes how good a menu is for the bombe.
+type Score struct {
+	// Loops is the number of independent loops.
+	Loops int
+	// Letters is the number of distinct letters.
+	Letters int
+	// Edges is the number of edges (the crib length).
+	Edges int
+	// Components is the number of connected parts.
+	Components int
+	// Largest is the number of letters in the largest connected part.
+	Largest int
+}
+
+// Score returns the score of the menu.
+func (m *Menu) Score() Score {
+	components := m.Components()
+	s := Score{
+		Letters:    len(m.Letters()),
+		Edges:      len(m.Edges),
+		Components: len(components),
+	}
+
+	// the number of independent loops of a graph is edges - vertices + components
+	s.Loops = s.Edges - s.Letters + s.Components
+
+	for _, c := range components {
+		if len(c) > s.Largest {
+			s.Largest = len(c)
+		}
+	}
+
+	return s
+}
+
+// Better reports whether s is a better menu score than other: more loops first, then a larger connected part and
+// then fewer connected parts.
+func (s Score) Better(other Score) bool {
+	if s.Loops != other.Loops {
+		return s.Loops > other.Loops
+	}
+
+	if s.Largest != other.Largest {
+		return s.Largest > other.Largest
+	}
+
+	return s.Components < other.Components
+}
+
+// RankedMenu is a menu built from a crib offset, with its score.
+type RankedMenu struct {
+	Offset int
+	Menu   *Menu
+	Score  Score
+}
+
+// RankMenus builds the menus for every valid offset of the crib in the ciphertext, with the best menus first.
+func RankMenus(ciphertext, plaintext string) []RankedMenu {
+	ranked := []RankedMenu{}
+
+	for _, offset := range crib.Positions(ciphertext, plaintext) {
+		m, err := BuildMenu(ciphertext, plaintext, offset)
+		if err != nil {
+			continue
+		}
+
+		ranked = append(ranked, RankedMenu{offset, m, m.Score()})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score.Better(ranked[j].Score)
+	})
+
+	return ranked
+}
+
+// DOT returns the menu as a graph in the Graphviz DOT language. Each edge is labeled with its position.
+func (m *Menu) DOT() string {
+	var sb strings.Builder
+
+	sb.WriteString("graph menu {\n")
+	for _, e := range m.Edges {
+		fmt.Fprintf(&sb, "  %c -- %c [label=\"%d\"];\n", e.From, e.To, e.Position)
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
@@ -0,0 +1,121 @@
+package bombe_test
+
+import (
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/bombe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildMenu(t *testing.T) {
+	menu, err := bombe.BuildMenu("xqgtt iczfw", "ATION", 5)
+	assert.NoError(t, err)
+
+	expected := []bombe.Edge{
+		{From: 'A', To: 'I', Position: 6},
+		{From: 'T', To: 'C', Position: 7},
+		{From: 'I', To: 'Z', Position: 8},
+		{From: 'O', To: 'F', Position: 9},
+		{From: 'N', To: 'W', Position: 10},
+	}
+	assert.Equal(t, expected, menu.Edges)
+	assert.Equal(t, []byte("ACFINOTWZ"), menu.Letters())
+	assert.Equal(t, byte('I'), menu.Central())
+}
+
+func TestBuildMenuError(t *testing.T) {
+	var tests = []struct {
+		cipher  string
+		crib    string
+		offset  int
+		message string
+	}{
+		{"ABC", "", 0, "the crib should have at least one letter"},
+		{"ABC", "XYZ", 1, "the crib does not fit in the ciphertext at offset 1"},
+		{"ABC", "X", -1, "the crib does not fit in the ciphertext at offset -1"},
+		{"ABC", "XB", 0, "the crib letter 'B' at offset 1 encodes to itself"},
+	}
+
+	for _, test := range tests {
+		_, err := bombe.BuildMenu(test.cipher, test.crib, test.offset)
+		assert.EqualError(t, err, test.message)
+	}
+}
+
+func TestLetters(t *testing.T) {
+	assert.Equal(t, "HELLOWORLD", bombe.Letters("Hello, World! 123"))
+}
+
+func TestMenuLoops(t *testing.T) {
+	menu := &bombe.Menu{Edges: []bombe.Edge{
+		{From: 'A', To: 'B', Position: 1},
+		{From: 'B', To: 'C', Position: 2},
+		{From: 'C', To: 'A', Position: 3},
+		{From: 'C', To: 'D', Position: 4},
+		{From: 'X', To: 'Y', Position: 5},
+		{From: 'Y', To: 'X', Position: 6},
+	}}
+
+	loops := menu.Loops()
+	assert.Len(t, loops, 2)
+
+	assert.Equal(t, []byte("BAC"), loops[0].Letters)
+	assert.Equal(t, []int{1, 3, 2}, positions(loops[0].Edges))
+
+	assert.Equal(t, []byte("YX"), loops[1].Letters)
+	assert.Equal(t, []int{5, 6}, positions(loops[1].Edges))
+}
+
+func positions(edges []bombe.Edge) []int {
+	result := []int{}
+
+	for _, e := range edges {
+		result = append(result, e.Position)
+	}
+
+	return result
+}
+
+func TestMenuComponentsAndScore(t *testing.T) {
+	menu := &bombe.Menu{Edges: []bombe.Edge{
+		{From: 'X', To: 'Y', Position: 1},
+		{From: 'A', To: 'B', Position: 2},
+		{From: 'B', To: 'C', Position: 3},
+		{From: 'C', To: 'A', Position: 4},
+	}}
+
+	assert.Equal(t, [][]byte{[]byte("ABC"), []byte("XY")}, menu.Components())
+	assert.Equal(t, bombe.Score{Loops: 1, Letters: 5, Edges: 4, Components: 2, Largest: 3}, menu.Score())
+	assert.Equal(t, byte('A'), menu.Central())
+	assert.Equal(t, byte(0), (&bombe.Menu{}).Central())
+
+	better := bombe.Score{Loops: 2, Largest: 2}
+	assert.True(t, better.Better(menu.Score()))
+	assert.False(t, menu.Score().Better(better))
+	assert.True(t, bombe.Score{Loops: 1, Largest: 4}.Better(menu.Score()))
+	assert.True(t, bombe.Score{Loops: 1, Largest: 3, Components: 1}.Better(menu.Score()))
+}
+
+func TestRankMenus(t *testing.T) {
+	ranked := bombe.RankMenus("BCAXXBCA", "ABC")
+	assert.Len(t, ranked, 4)
+
+	assert.Equal(t, 0, ranked[0].Offset)
+	assert.Equal(t, 1, ranked[0].Score.Loops)
+	assert.Equal(t, 5, ranked[1].Offset)
+	assert.Equal(t, 1, ranked[1].Score.Loops)
+	assert.Equal(t, 0, ranked[2].Score.Loops)
+}
+
+func TestMenuDOT(t *testing.T) {
+	menu, _ := bombe.BuildMenu("XQGTTICZFW", "ATION", 5)
+	expected := `graph menu {
+  A -- I [label="6"];
+  T -- C [label="7"];
+  I -- Z [label="8"];
+  O -- F [label="9"];
+  N -- W [label="10"];
+}
+`
+	assert.Equal(t, expected, menu.DOT())
+}
@@ -0,0 +1,256 @@
+// Package bruteforce searches the rotor order, reflector, ring and window settings of a message with a known crib,
+// testing every configuration allowed by a set of constraints.
+//
+// The search is only feasible when the plugboard is known (or empty), since the plugboard is not part of the search.
+package bruteforce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/ibraimgm/enigma/machine/parts"
+	"github.com/ibraimgm/enigma/text"
+)
+
+// Constraints limits the configurations tested by the search.
+type Constraints struct {
+	// Rotors available, tested in every order without repetition. Empty means the rotors of the Enigma I (I to V).
+	Rotors []string
+	// Reflectors to test. Empty means only "B".
+	Reflectors []string
+	// Rings to test. Empty means every ring setting of the middle and fast rotors, with 'A' in the slow rotor (the
+	// slow ring does not need to be tested, since it is equivalent to a change in the slow window).
+	Rings []string
+	// Windows to test. Empty means every window setting.
+	Windows []string
+	// Plugs is the known plugboard, in the compact notation (ex: "ABCD" means A-B and C-D).
+	Plugs string
+	// Workers is the number of goroutines used in the search. Zero means one per CPU.
+	Workers int
+	// Part and Parts split the search in Parts disjoint pieces, so it can be spread over several processes or
+	// machines. Only the jobs of piece Part (from 0 to Parts-1) are tested. Zero Parts means the whole search.
+	Parts int
+	Part  int
+}
+
+// Job is a unit of work of the search: a rotor order, reflector and ring setting, tested with every window.
+type Job struct {
+	Reflector string
+	Slow      string
+	Middle    string
+	Fast      string
+	Ring      string
+}
+
+// Jobs returns every job allowed by the constraints, always in the same order. The job k belongs to the part
+// k % Parts and is tested by the worker (k / Parts) % Workers of that part.
+func Jobs(c Constraints) []Job {
+	c = withDefaults(c)
+	jobs := []Job{}
+
+	for _, reflector := range c.Reflectors {
+		for _, order := range enigma.RotorOrders(c.Rotors) {
+			for _, ring := range c.Rings {
+				jobs = append(jobs, Job{reflector, order[0], order[1], order[2], ring})
+			}
+		}
+	}
+
+	return jobs
+}
+
+func withDefaults(c Constraints) Constraints {
+	if len(c.Rotors) == 0 {
+		c.Rotors = enigma.Models["I"].Rotors
+	}
+
+	if len(c.Reflectors) == 0 {
+		c.Reflectors = []string{"B"}
+	}
+
+	if len(c.Rings) == 0 {
+		c.Rings = settingsList(false)
+	}
+
+	if len(c.Windows) == 0 {
+		c.Windows = settingsList(true)
+	}
+
+	if c.Workers <= 0 {
+		c.Workers = runtime.NumCPU()
+	}
+
+	if c.Parts <= 0 {
+		c.Parts, c.Part = 1, 0
+	}
+
+	return c
+}
+
+// settingsList returns the three-letter settings in alphabetical order: every one of them, or only the ones with
+// 'A' in the first letter
+func settingsList(all bool) []string {
+	first := 1
+	if all {
+		first = 26
+	}
+
+	list := make([]string, 0, first*26*26)
+	for s := 0; s < first; s++ {
+		for m := 0; m < 26; m++ {
+			for f := 0; f < 26; f++ {
+				list = append(list, string([]byte{byte('A' + s), byte('A' + m), byte('A' + f)}))
+			}
+		}
+	}
+
+	return list
+}
+
+// Search tests every configuration allowed by the constraints, looking for the ones that encode the crib into the
+// ciphertext at the specified offset (starting with zero). Only the letters are considered, both in the ciphertext
+// and in the crib. The settings found are sent to the returned channel, with the window of the first letter of the
+// message; the channel is closed when the search ends or when ctx is cancelled.
+//
+// Short cribs match many equivalent settings: for example, a change in the fast ring together with the same change
+// in the fast window only makes a difference if the middle rotor turns over while the crib is encoded.
+func Search(ctx context.Context, ciphertext, crib string, offset int, c Constraints) (<-chan enigma.Settings, error) {
+	c = withDefaults(c)
+	cipher, plain := text.Letters(ciphertext), text.Letters(crib)
+
+	if len(plain) == 0 {
+		return nil, errors.New("the crib should have at least one letter")
+	}
+
+	if offset < 0 || offset+len(plain) > len(cipher) {
+		return nil, fmt.Errorf("the crib does not fit in the ciphertext at offset %d", offset)
+	}
+
+	if c.Part < 0 || c.Part >= c.Parts {
+		return nil, fmt.Errorf("part should be between 0 and %d", c.Parts-1)
+	}
+
+	all := Jobs(c)
+	if len(all) == 0 {
+		return nil, errors.New("the constraints should allow at least one rotor order")
+	}
+
+	if err := validate(c, all[0]); err != nil {
+		return nil, err
+	}
+
+	jobs := []Job{}
+	for k, job := range all {
+		if k%c.Parts == c.Part {
+			jobs = append(jobs, job)
+		}
+	}
+
+	matches := make(chan enigma.Settings)
+	var wg sync.WaitGroup
+
+	for w := 0; w < c.Workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			for k := w; k < len(jobs); k += c.Workers {
+				if !jobs[k].run(ctx, cipher[offset:offset+len(plain)], plain, offset, c, matches) {
+					return
+				}
+			}
+		}(w)
+	}
+
+	go func() {
+		wg.Wait()
+		close(matches)
+	}()
+
+	return matches, nil
+}
+
+// validate checks the rotors, reflectors, rings, windows and plugs of the constraints once, so the jobs do not need
+// to check them
+func validate(c Constraints, job Job) error {
+	for _, id := range c.Rotors {
+		if _, err := parts.GetRotor(id); err != nil {
+			return err
+		}
+	}
+
+	var e enigma.Enigma
+	for _, reflector := range c.Reflectors {
+		job.Reflector = reflector
+
+		var err error
+		if e, err = enigma.WithSettings(job.settings(c.Plugs, "AAA")); err != nil {
+			return err
+		}
+	}
+
+	for _, ring := range c.Rings {
+		if err := e.SetRing(ring); err != nil {
+			return err
+		}
+	}
+
+	for _, window := range c.Windows {
+		if err := e.SetWindow(window); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (job Job) settings(plugs, window string) enigma.Settings {
+	return enigma.Settings{
+		Reflector: job.Reflector,
+		Slow:      job.Slow,
+		Middle:    job.Middle,
+		Fast:      job.Fast,
+		Ring:      job.Ring,
+		Window:    window,
+		Plugs:     plugs,
+	}
+}
+
+// run tests every window of the job, and returns false if the search was cancelled
+func (job Job) run(ctx context.Context, cipher, plain string, offset int, c Constraints, matches chan<- enigma.Settings) bool {
+	e, _ := enigma.WithSettings(job.settings(c.Plugs, "AAA"))
+	if compiled, err := enigma.Compile(e); err == nil {
+		e = compiled
+	}
+
+	for i, window := range c.Windows {
+		if i%676 == 0 && ctx.Err() != nil {
+			return false
+		}
+
+		e.SetWindow(window)
+		e.Advance(uint64(offset))
+
+		found := true
+		for j := range plain {
+			if r, _ := e.Encode(rune(plain[j])); byte(r) != cipher[j] {
+				found = false
+				break
+			}
+		}
+
+		if found {
+			select {
+			case matches <- job.settings(c.Plugs, window):
+			case <-ctx.Done():
+				return false
+			}
+		}
+	}
+
+	return true
+}
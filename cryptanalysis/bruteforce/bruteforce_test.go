@@ -0,0 +1,125 @@
+package bruteforce_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/bruteforce"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+const message = "WETTERVORHERSAGEBISKAYAXKEINEBESONDERENVORKOMMNISSE"
+
+func encode(t *testing.T, settings enigma.Settings) string {
+	e, err := enigma.WithSettings(settings)
+	assert.NoError(t, err)
+
+	return e.EncodeMessage(message, 0)
+}
+
+func collect(matches <-chan enigma.Settings) []enigma.Settings {
+	found := []enigma.Settings{}
+
+	for s := range matches {
+		found = append(found, s)
+	}
+
+	return found
+}
+
+func TestJobs(t *testing.T) {
+	jobs := bruteforce.Jobs(bruteforce.Constraints{})
+	assert.Len(t, jobs, 60*676)
+	assert.Equal(t, bruteforce.Job{Reflector: "B", Slow: "I", Middle: "II", Fast: "III", Ring: "AAA"}, jobs[0])
+	assert.Equal(t, bruteforce.Job{Reflector: "B", Slow: "I", Middle: "II", Fast: "III", Ring: "AAB"}, jobs[1])
+
+	jobs = bruteforce.Jobs(bruteforce.Constraints{Rotors: []string{"I", "II", "III"}, Reflectors: []string{"B", "C"}, Rings: []string{"AAA"}})
+	assert.Len(t, jobs, 12)
+	assert.Equal(t, "C", jobs[6].Reflector)
+}
+
+func TestSearch(t *testing.T) {
+	key := enigma.Settings{Reflector: "B", Slow: "II", Middle: "IV", Fast: "I", Ring: "AFK", Window: "XMJ", Plugs: "ARGKOX"}
+	cipher := encode(t, key)
+
+	c := bruteforce.Constraints{
+		Rotors:  []string{"I", "II", "IV"},
+		Rings:   []string{"AAA", "AFK", "BCD"},
+		Plugs:   "ARGKOX",
+		Workers: 3,
+	}
+
+	matches, err := bruteforce.Search(context.Background(), cipher, "BISKAYAXKEINEBESONDEREN", 16, c)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []enigma.Settings{key}, collect(matches))
+}
+
+func TestSearchParts(t *testing.T) {
+	key := enigma.Settings{Reflector: "B", Slow: "I", Middle: "II", Fast: "III", Ring: "AAA", Window: "QEV"}
+	cipher := encode(t, key)
+	c := bruteforce.Constraints{Rotors: []string{"I", "II", "III"}, Rings: []string{"AAA"}, Parts: 2}
+
+	total := 0
+	for part := 0; part < 2; part++ {
+		c.Part = part
+		matches, err := bruteforce.Search(context.Background(), cipher, "WETTERVORHERSAGE", 0, c)
+		assert.NoError(t, err)
+
+		found := collect(matches)
+		total += len(found)
+
+		if part == 0 {
+			assert.Equal(t, []enigma.Settings{key}, found)
+		}
+	}
+
+	assert.Equal(t, 1, total)
+}
+
+func TestSearchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	matches, err := bruteforce.Search(ctx, "ABCDEF", "XYZ", 0, bruteforce.Constraints{})
+	assert.NoError(t, err)
+	assert.Empty(t, collect(matches))
+}
+
+func TestSearchErrors(t *testing.T) {
+	var tests = []struct {
+		cipher   string
+		crib     string
+		offset   int
+		c        bruteforce.Constraints
+		expected string
+	}{
+		{"ABCDEF", "", 0, bruteforce.Constraints{}, "the crib should have at least one letter"},
+		{"ABCDEF", "XYZ", 4, bruteforce.Constraints{}, "the crib does not fit in the ciphertext at offset 4"},
+		{"ABCDEF", "XYZ", -1, bruteforce.Constraints{}, "the crib does not fit in the ciphertext at offset -1"},
+		{"ABCDEF", "XYZ", 0, bruteforce.Constraints{Parts: 2, Part: 2}, "part should be between 0 and 1"},
+	}
+
+	for _, test := range tests {
+		_, err := bruteforce.Search(context.Background(), test.cipher, test.crib, test.offset, test.c)
+		assert.EqualError(t, err, test.expected)
+	}
+
+	_, err := bruteforce.Search(context.Background(), "ABCDEF", "XYZ", 0, bruteforce.Constraints{Rotors: []string{"I", "II", "X"}})
+	assert.Error(t, err)
+
+	_, err = bruteforce.Search(context.Background(), "ABCDEF", "XYZ", 0, bruteforce.Constraints{Windows: []string{"A1"}})
+	assert.Error(t, err)
+
+	_, err = bruteforce.Search(context.Background(), "ABCDEF", "XYZ", 0, bruteforce.Constraints{Reflectors: []string{"B", "Z"}})
+	assert.EqualError(t, err, "unknown reflector: 'Z'")
+
+	_, err = bruteforce.Search(context.Background(), "ABCDEF", "XYZ", 0, bruteforce.Constraints{Rings: []string{"AAA", "A1"}})
+	assert.Error(t, err)
+
+	_, err = bruteforce.Search(context.Background(), "ABCDEF", "XYZ", 0, bruteforce.Constraints{Rotors: []string{"I", "II"}})
+	assert.EqualError(t, err, "the constraints should allow at least one rotor order")
+}
@@ -0,0 +1,341 @@
+// Package crack implements a ciphertext-only attack against the enigma, as described by James Gillogly ("Ciphertext-only
+// Cryptanalysis of Enigma", 1995) and improved by Heidi Williams, Geoff Sullivan and Frode Weierud.
+//
+// The attack has three steps: first, every rotor order and start position is tested without plugboard, and the
+// positions that decode to the text with the highest index of coincidence are kept. Then, the ring settings of the
+// middle and fast rotors are recovered for each candidate, and at last the plugboard is recovered by hill climbing,
+// first with the index of coincidence and then with n-gram scores.
+package crack
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/plugboard"
+	"github.com/ibraimgm/enigma/cryptanalysis/scoring"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/ibraimgm/enigma/text"
+)
+
+// Options controls the search.
+type Options struct {
+	// Reflectors to test. Empty means only "B".
+	Reflectors []string
+	// Orders is the list of rotor orders (slow, middle and fast) to test. Empty means every order of the rotors of
+	// the Enigma I (rotors I to V).
+	Orders [][3]string
+	// Candidates is the number of start positions kept for the ring and plugboard search. Zero means 10.
+	Candidates int
+	// MaxPlugs is the maximum number of plug pairs. Zero means 10.
+	MaxPlugs int
+	// Scorer used to recover the plugboard and to choose the best candidate. Nil means the German bigrams.
+	Scorer scoring.Scorer
+	// Workers is the number of goroutines used in the search. Zero means one per CPU.
+	Workers int
+	// Progress, if not nil, is called after each rotor order is tested, with the number of orders tested so far and
+	// the total. It is always called from the goroutine that called Crack.
+	Progress func(done, total int)
+}
+
+// Result is the best key found by the search.
+type Result struct {
+	Settings  enigma.Settings
+	Plaintext string
+	Score     float64
+}
+
+type candidate struct {
+	settings enigma.Settings
+	score    float64
+}
+
+// Crack searches the key of the ciphertext. Only the letters of the ciphertext are considered.
+// The search can be cancelled with ctx, in which case the error of the context is returned.
+func Crack(ctx context.Context, ciphertext string, options Options) (Result, error) {
+	options, err := withDefaults(options)
+	if err != nil {
+		return Result{}, err
+	}
+
+	letters := text.Letters(ciphertext)
+	cipher := scoring.Letters(letters)
+
+	if len(cipher) < 2 {
+		return Result{}, errors.New("the ciphertext should have at least 2 letters")
+	}
+
+	jobs := []enigma.Settings{}
+	for _, reflector := range options.Reflectors {
+		for _, order := range options.Orders {
+			s := enigma.Settings{Reflector: reflector, Slow: order[0], Middle: order[1], Fast: order[2]}
+			if _, err := enigma.WithSettings(s); err != nil {
+				return Result{}, err
+			}
+
+			jobs = append(jobs, s)
+		}
+	}
+
+	candidates, err := searchPositions(ctx, cipher, jobs, options)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return searchKeys(ctx, letters, cipher, candidates, options)
+}
+
+func withDefaults(options Options) (Options, error) {
+	if len(options.Reflectors) == 0 {
+		options.Reflectors = []string{"B"}
+	}
+
+	if len(options.Orders) == 0 {
+		options.Orders = enigma.Models["I"].Orders()
+	}
+
+	if options.Candidates <= 0 {
+		options.Candidates = 10
+	}
+
+	if options.MaxPlugs <= 0 {
+		options.MaxPlugs = 10
+	}
+
+	if options.Scorer == nil {
+		bigrams, err := scoring.Builtin(scoring.German, 2)
+		if err != nil {
+			return options, err
+		}
+
+		options.Scorer = bigrams
+	}
+
+	if options.Workers <= 0 {
+		options.Workers = runtime.NumCPU()
+	}
+
+	return options, nil
+}
+
+// searchPositions tests every start position of each job (a reflector and rotor order), and returns the best ones
+func searchPositions(ctx context.Context, cipher []byte, jobs []enigma.Settings, options Options) ([]candidate, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	work := make(chan enigma.Settings)
+	results := make(chan []candidate)
+	var wg sync.WaitGroup
+
+	for i := 0; i < options.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range work {
+				results <- searchOrder(ctx, cipher, job, options.Candidates)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(work)
+		for _, job := range jobs {
+			select {
+			case work <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	best := []candidate{}
+	done := 0
+	for r := range results {
+		best = append(best, r...)
+		done++
+
+		if options.Progress != nil {
+			options.Progress(done, len(jobs))
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return top(best, options.Candidates), nil
+}
+
+// searchOrder tests every start position of a rotor order, with rings 'AAA' and without plugboard
+func searchOrder(ctx context.Context, cipher []byte, settings enigma.Settings, keep int) []candidate {
+	t, err := enigma.NewPermutationCache(settings)
+	if err != nil {
+		return nil
+	}
+
+	best := []candidate{}
+	plain := make([]byte, len(cipher))
+
+	for start := 0; start < enigma.Positions; start++ {
+		if start%676 == 0 && ctx.Err() != nil {
+			return nil
+		}
+
+		p := start
+		for i, c := range cipher {
+			plain[i] = t.Permutation(p)[c]
+			p = t.Next(p)
+		}
+
+		score := scoring.IoC.Score(plain)
+		if len(best) < keep || score > best[len(best)-1].score {
+			s := settings
+			s.Window = enigma.PositionToWindow(start)
+			best = top(append(best, candidate{s, score}), keep)
+		}
+	}
+
+	return best
+}
+
+// top sorts the candidates by score and returns the best n ones
+func top(candidates []candidate, n int) []candidate {
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+
+	return candidates
+}
+
+// searchKeys recovers the ring settings and the plugboard of each candidate, returning the best key found after
+// a last ring search with the scorer. The ciphertext is given both as letters and as values from 0 to 25.
+func searchKeys(ctx context.Context, letters string, cipher []byte, candidates []candidate, options Options) (Result, error) {
+	results := make([]Result, len(candidates))
+	errs := make([]error, len(candidates))
+	sem := make(chan struct{}, options.Workers)
+	var wg sync.WaitGroup
+
+	for i := range candidates {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			settings := searchRings(cipher, candidates[i].settings, scoring.IoC)
+			results[i], errs[i] = searchPlugs(letters, settings, options.MaxPlugs, options.Scorer)
+		}(i)
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	best := results[0]
+	for _, r := range results[1:] {
+		if r.Score > best.Score {
+			best = r
+		}
+	}
+
+	// with the plugboard known, the scorer can find the exact moment the middle and slow rotors turn over
+	settings := searchRings(cipher, best.Settings, options.Scorer)
+	e, _ := enigma.WithSettings(settings)
+	plain := e.EncodeMessage(letters, 0)
+
+	return Result{Settings: settings, Plaintext: plain, Score: options.Scorer.Score(scoring.Letters(plain))}, nil
+}
+
+// searchRings tests every ring setting of the middle and fast rotors, moving the window by the same amount, so the
+// only change in the decoded text is the moment the rotors turn over
+func searchRings(cipher []byte, settings enigma.Settings, scorer scoring.Scorer) enigma.Settings {
+	if settings.Ring != "" {
+		settings = withRingA(settings)
+	}
+
+	best := settings
+	bestScore := scorer.Score(decode(cipher, best))
+
+	for middle := 0; middle < 26; middle++ {
+		for fast := 0; fast < 26; fast++ {
+			s := settings
+			s.Ring = string([]byte{'A', byte('A' + middle), byte('A' + fast)})
+			s.Window = string([]byte{
+				settings.Window[0],
+				byte('A' + (int(settings.Window[1]-'A')+middle)%26),
+				byte('A' + (int(settings.Window[2]-'A')+fast)%26),
+			})
+
+			if score := scorer.Score(decode(cipher, s)); score > bestScore {
+				best, bestScore = s, score
+			}
+		}
+	}
+
+	return best
+}
+
+// withRingA returns the same settings with rings 'AAA' in the middle and fast rotors, moving the window to keep
+// the same wiring in the first keypress
+func withRingA(settings enigma.Settings) enigma.Settings {
+	s := settings
+	s.Ring = string([]byte{settings.Ring[0], 'A', 'A'})
+	s.Window = string([]byte{
+		settings.Window[0],
+		byte('A' + (int(settings.Window[1])-int(settings.Ring[1])+26)%26),
+		byte('A' + (int(settings.Window[2])-int(settings.Ring[2])+26)%26),
+	})
+
+	return s
+}
+
+// searchPlugs recovers the plugboard by hill climbing, first with the index of coincidence and then with the scorer
+func searchPlugs(ciphertext string, settings enigma.Settings, maxPlugs int, scorer scoring.Scorer) (Result, error) {
+	settings.Plugs = ""
+	e, err := enigma.WithSettings(settings)
+	if err != nil {
+		return Result{}, err
+	}
+
+	r, err := plugboard.Recover(e, ciphertext, plugboard.Options{MaxPlugs: maxPlugs, Scorer: scorer})
+	if err != nil {
+		return Result{}, err
+	}
+
+	settings.Plugs = r.Plugs
+	return Result{Settings: settings, Plaintext: r.Plaintext, Score: r.Score}, nil
+}
+
+func decode(cipher []byte, settings enigma.Settings) []byte {
+	e, _ := enigma.WithSettings(settings)
+	plain := make([]byte, len(cipher))
+
+	for i, c := range cipher {
+		p, _ := e.Encode(rune('A' + c))
+		plain[i] = byte(p - 'A')
+	}
+
+	return plain
+}
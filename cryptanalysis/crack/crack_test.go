@@ -0,0 +1,67 @@
+package crack_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/crack"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+const plaintext = "NEHMENDXLUFTDRUCKSTEIGENDZZTEMPERATURZWOELFGRADXFUERDIENAQTWIRDNEBELINDENKUESTENGEBIETENERWARTETXDIE" +
+	"SQIFFESOLLENDENHAFENERSTNAQSONNENAUFGANGVERLASSENUNDDIEBEFOHLENEPOSITIONBISZUMABENDERREIQENXDERKOMMA" +
+	"NDANTMELDETZZDASSDASBOOTEINSATZBEREITISTUNDDIEBESATZUNGVOLLSTAENDIGANBORDISTXFEINDLIQEZERSTOERERWURD" +
+	"ENIMQUADRATNOERDLIQDERINSELGESIQTETXESWIRDBEFOHLENZZDIEFUNKSTILLEBISAUFWEITERESEINZUHALTENUNDNURINDR" +
+	"INGENDENFAELLENZUSENDENXDASOBERKOMMANDOGIBTBEKANNTZZDASSDIETRUPPENINDERVERGANGENENNAQTDIESTELLUNGENA" +
+	"MFLUSSGEHALTENHABENXDIEVERSORGUNGMITMUNITIONUNDVERPFLEGUNGISTGESIQERTZZJEDOQFEHLENTREIBSTOFFUNDERSAT" +
+	"ZTEILEFUERDIEFAHRZEUGEXDIEDIVISIONMELDETSTARKEVERLUSTEUNDBITTETUMVERSTAERKUNGXDERANGRIFFSOLLAMFRUEHE" +
+	"NMORGENBEGINNENZZSOBALDDIEARTILLERIEDASFEUEREROEFFNETHATXALLEEINHEITENHABENIHREBEREITSQAFTBISZWEIUHR"
+
+func encode(t *testing.T, settings enigma.Settings) string {
+	e, err := enigma.WithSettings(settings)
+	assert.Nil(t, err)
+
+	return e.EncodeMessage(plaintext, 0)
+}
+
+func TestCrack(t *testing.T) {
+	settings := enigma.Settings{
+		Reflector: "B",
+		Slow:      "II",
+		Middle:    "V",
+		Fast:      "III",
+		Ring:      "AMF",
+		Window:    "QDR",
+		Plugs:     "AVBSCGDLFUHZINKM",
+	}
+
+	progress := 0
+	options := crack.Options{
+		Orders:   [][3]string{{"I", "II", "III"}, {"II", "V", "III"}},
+		Progress: func(done, total int) { progress = done; assert.Equal(t, 2, total) },
+	}
+
+	result, err := crack.Crack(context.Background(), encode(t, settings), options)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, progress)
+	assert.Equal(t, plaintext, result.Plaintext)
+	assert.Equal(t, [3]string{"II", "V", "III"}, [3]string{result.Settings.Slow, result.Settings.Middle, result.Settings.Fast})
+	assert.Equal(t, settings.Plugs, result.Settings.Plugs)
+}
+
+func TestCrackCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := crack.Crack(ctx, plaintext, crack.Options{})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestCrackErrors(t *testing.T) {
+	_, err := crack.Crack(context.Background(), "A", crack.Options{})
+	assert.NotNil(t, err)
+
+	_, err = crack.Crack(context.Background(), plaintext, crack.Options{Orders: [][3]string{{"I", "II", "IX"}}})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,80 @@
+// Package crib finds where a crib (a piece of known plaintext) can be placed in an enigma ciphertext.
+// Since the enigma never encodes a letter to itself, every offset where a crib letter is equal to the ciphertext
+// letter below it can be discarded.
+package crib
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ibraimgm/enigma/text"
+)
+
+// Positions returns every offset (starting with zero) where the crib can be placed in the ciphertext without
+// collisions. Only the letters are considered, both in the ciphertext and in the crib; case is ignored.
+func Positions(ciphertext, crib string) []int {
+	cipher := text.Letters(ciphertext)
+	plain := text.Letters(crib)
+	positions := []int{}
+
+	if len(plain) == 0 {
+		return positions
+	}
+
+	for offset := 0; offset+len(plain) <= len(cipher); offset++ {
+		if len(collisions(cipher, plain, offset)) == 0 {
+			positions = append(positions, offset)
+		}
+	}
+
+	return positions
+}
+
+// Collisions returns the indexes of the crib letters that are equal to the ciphertext letters when the crib is placed
+// at offset. A crib that does not fit in the ciphertext at offset has no collisions.
+func Collisions(ciphertext, crib string, offset int) []int {
+	return collisions(text.Letters(ciphertext), text.Letters(crib), offset)
+}
+
+// Visualize shows the alignment of the crib at offset: the offset and the ciphertext letters in the first line, the
+// crib in the second one and, if there are any collisions, a third line marking them with '^'.
+func Visualize(ciphertext, crib string, offset int) string {
+	cipher := text.Letters(ciphertext)
+	plain := text.Letters(crib)
+
+	if offset < 0 || offset+len(plain) > len(cipher) {
+		return ""
+	}
+
+	var sb strings.Builder
+	prefix := fmt.Sprintf("%5d  ", offset)
+	fmt.Fprintf(&sb, "%s%s\n", prefix, cipher[offset:offset+len(plain)])
+	fmt.Fprintf(&sb, "%s%s\n", strings.Repeat(" ", len(prefix)), plain)
+
+	if c := collisions(cipher, plain, offset); len(c) > 0 {
+		marks := []byte(strings.Repeat(" ", len(plain)))
+		for _, i := range c {
+			marks[i] = '^'
+		}
+
+		fmt.Fprintf(&sb, "%s%s\n", strings.Repeat(" ", len(prefix)), strings.TrimRight(string(marks), " "))
+	}
+
+	return sb.String()
+}
+
+func collisions(cipher, plain string, offset int) []int {
+	result := []int{}
+
+	if offset < 0 || offset+len(plain) > len(cipher) {
+		return result
+	}
+
+	for i := range plain {
+		if plain[i] == cipher[offset+i] {
+			result = append(result, i)
+		}
+	}
+
+	return result
+}
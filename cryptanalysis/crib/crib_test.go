@@ -0,0 +1,42 @@
+package crib_test
+
+import (
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/crib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPositions(t *testing.T) {
+	var tests = []struct {
+		cipher   string
+		crib     string
+		expected []int
+	}{
+		{"ABCDE", "XY", []int{0, 1, 2, 3}},
+		{"ABCDE", "BC", []int{0, 2, 3}},
+		{"abc de", "b c", []int{0, 2, 3}},
+		{"ABCDE", "ABCDE", []int{}},
+		{"ABCDE", "VWXYZ", []int{0}},
+		{"ABC", "VWXYZ", []int{}},
+		{"ABC", "", []int{}},
+	}
+
+	for _, test := range tests {
+		actual := crib.Positions(test.cipher, test.crib)
+		assert.Equal(t, test.expected, actual)
+	}
+}
+
+func TestCollisions(t *testing.T) {
+	assert.Equal(t, []int{0, 1}, crib.Collisions("ABCDE", "BCX", 1))
+	assert.Equal(t, []int{}, crib.Collisions("ABCDE", "XYZ", 1))
+	assert.Equal(t, []int{}, crib.Collisions("ABCDE", "XYZ", 3))
+}
+
+func TestVisualize(t *testing.T) {
+	assert.Equal(t, "    2  CDE\n       XYZ\n", crib.Visualize("ABCDE", "XYZ", 2))
+	assert.Equal(t, "    1  BCD\n       BXD\n       ^ ^\n", crib.Visualize("ABCDE", "BXD", 1))
+	assert.Equal(t, "    0  ABC\n       XBZ\n        ^\n", crib.Visualize("ABCDE", "XBZ", 0))
+	assert.Equal(t, "", crib.Visualize("ABCDE", "XYZ", 3))
+}
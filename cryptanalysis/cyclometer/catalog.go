@@ -0,0 +1,214 @@
+package cyclometer
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ibraimgm/enigma/internal/count"
+	"github.com/ibraimgm/enigma/machine/enigma"
+)
+
+// Entry is a rotor order and a Grundstellung (the window setting used to encode the indicators), relative to the
+// ring setting 'AAA'.
+type Entry struct {
+	Reflector string
+	Slow      string
+	Middle    string
+	Fast      string
+	Window    string
+}
+
+// Catalog has the characteristic of every rotor order and position.
+type Catalog struct {
+	Reflector string
+	Orders    [][3]string
+	// characteristics has, for each order, the characteristic of each position (in the notation of String)
+	characteristics [][]string
+	index           map[string][]Entry
+}
+
+// BuildCatalog computes the characteristic of every position of each rotor order, with the ring setting 'AAA' and
+// no plugboard, as the Polish did with the cyclometer. Unlike the cyclometer, the turnover of the middle and slow
+// rotors is simulated.
+func BuildCatalog(reflector string, orders [][3]string) (*Catalog, error) {
+	c := &Catalog{Reflector: reflector, Orders: orders, characteristics: make([][]string, len(orders))}
+
+	for o, order := range orders {
+		cache, err := enigma.NewPermutationCache(enigma.Settings{
+			Reflector: reflector,
+			Slow:      order[0],
+			Middle:    order[1],
+			Fast:      order[2],
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		c.characteristics[o] = characteristics(cache)
+	}
+
+	c.buildIndex()
+	return c, nil
+}
+
+// characteristics returns the characteristic of every position of the machine
+func characteristics(cache *enigma.PermutationCache) []string {
+	result := make([]string, enigma.Positions)
+
+	for start := 0; start < enigma.Positions; start++ {
+		// the positions of the six keypresses of the indicator
+		var steps [6]int
+		p := start
+		for i := range steps {
+			steps[i] = p
+			p = cache.Next(p)
+		}
+
+		var c Characteristic
+		for i := 0; i < 3; i++ {
+			// both permutations are involutions, so the inverse of the first one is itself
+			var product [26]byte
+			for x := range product {
+				product[x] = cache.Permutation(steps[i+3])[cache.Permutation(steps[i])[x]]
+			}
+
+			c[i] = cycles(&product)
+		}
+
+		result[start] = c.String()
+	}
+
+	return result
+}
+
+func (c *Catalog) buildIndex() {
+	c.index = make(map[string][]Entry)
+
+	for o, order := range c.Orders {
+		for p, ch := range c.characteristics[o] {
+			c.index[ch] = append(c.index[ch], Entry{c.Reflector, order[0], order[1], order[2], enigma.PositionToWindow(p)})
+		}
+	}
+}
+
+// Lookup returns every rotor order and Grundstellung with the characteristic.
+func (c *Catalog) Lookup(ch Characteristic) []Entry {
+	return c.index[ch.String()]
+}
+
+// Characteristic returns the characteristic of a rotor order and Grundstellung in the catalog.
+func (c *Catalog) Characteristic(order [3]string, window string) (Characteristic, bool) {
+	p, err := enigma.WindowToPosition(window)
+	if err != nil {
+		return Characteristic{}, false
+	}
+
+	for o := range c.Orders {
+		if c.Orders[o] == order {
+			ch, err := ParseCharacteristic(c.characteristics[o][p])
+			return ch, err == nil
+		}
+	}
+
+	return Characteristic{}, false
+}
+
+// Size returns the number of distinct characteristics in the catalog.
+func (c *Catalog) Size() int {
+	return len(c.index)
+}
+
+// catalogFile is the format of a catalog saved on disk
+type catalogFile struct {
+	Reflector       string
+	Orders          [][3]string
+	Characteristics [][]string
+}
+
+// WriteTo saves the catalog, in a format that can be read with ReadCatalog.
+func (c *Catalog) WriteTo(w io.Writer) (int64, error) {
+	cw := &count.Writer{W: w}
+	err := gob.NewEncoder(cw).Encode(catalogFile{c.Reflector, c.Orders, c.characteristics})
+
+	return cw.N, err
+}
+
+// ReadCatalog reads a catalog saved with WriteTo.
+func ReadCatalog(r io.Reader) (*Catalog, error) {
+	var f catalogFile
+
+	if err := gob.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+
+	if len(f.Characteristics) != len(f.Orders) {
+		return nil, errors.New("invalid catalog: wrong number of rotor orders")
+	}
+
+	for _, ch := range f.Characteristics {
+		if len(ch) != enigma.Positions {
+			return nil, errors.New("invalid catalog: wrong number of positions")
+		}
+	}
+
+	c := &Catalog{Reflector: f.Reflector, Orders: f.Orders, characteristics: f.Characteristics}
+	c.buildIndex()
+
+	return c, nil
+}
+
+// LoadCatalog reads the catalog saved in the file name, if it exists and has the same reflector and rotor orders.
+// Otherwise, the catalog is built and saved in the file, creating the parent directories if needed.
+func LoadCatalog(name, reflector string, orders [][3]string) (*Catalog, error) {
+	if f, err := os.Open(name); err == nil {
+		c, err := ReadCatalog(f)
+		f.Close()
+
+		if err == nil && c.Reflector == reflector && sameOrders(c.Orders, orders) {
+			return c, nil
+		}
+	}
+
+	c, err := BuildCatalog(reflector, orders)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.WriteTo(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	// a failed close can leave the file truncated
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func sameOrders(a, b [][3]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,71 @@
+package cyclometer_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/cyclometer"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+var orders = [][3]string{{"I", "II", "III"}, {"II", "I", "III"}}
+
+func TestCatalogLookup(t *testing.T) {
+	catalog, err := cyclometer.BuildCatalog("B", orders)
+	assert.NoError(t, err)
+	assert.True(t, catalog.Size() > 1000)
+
+	// the plugboard does not change the characteristic
+	daily := enigma.Settings{Reflector: "B", Slow: "II", Middle: "I", Fast: "III", Ring: "AAA", Window: "KDR", Plugs: "AQBNCYEOFZ"}
+	c, err := cyclometer.FromIndicators(indicators(t, daily, 150))
+	assert.NoError(t, err)
+
+	expected, ok := catalog.Characteristic([3]string{"II", "I", "III"}, "KDR")
+	assert.True(t, ok)
+	assert.Equal(t, expected, c)
+
+	entries := catalog.Lookup(c)
+	assert.Contains(t, entries, cyclometer.Entry{Reflector: "B", Slow: "II", Middle: "I", Fast: "III", Window: "KDR"})
+	assert.True(t, len(entries) < 20)
+
+	_, ok = catalog.Characteristic([3]string{"III", "II", "I"}, "KDR")
+	assert.False(t, ok)
+
+	_, err = cyclometer.BuildCatalog("X", orders)
+	assert.Error(t, err)
+}
+
+func TestCatalogFile(t *testing.T) {
+	catalog, err := cyclometer.BuildCatalog("B", orders[:1])
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	n, err := catalog.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	read, err := cyclometer.ReadCatalog(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, catalog.Size(), read.Size())
+	assert.Equal(t, orders[:1], read.Orders)
+
+	_, err = cyclometer.ReadCatalog(bytes.NewReader([]byte("garbage")))
+	assert.Error(t, err)
+
+	name := filepath.Join(t.TempDir(), "cache", "catalog.gob")
+	loaded, err := cyclometer.LoadCatalog(name, "B", orders[:1])
+	assert.NoError(t, err)
+	assert.Equal(t, catalog.Size(), loaded.Size())
+
+	info, err := os.Stat(name)
+	assert.NoError(t, err)
+	assert.True(t, info.Size() > 0)
+
+	// a cached catalog with other orders is rebuilt
+	loaded, err = cyclometer.LoadCatalog(name, "B", orders[1:])
+	assert.NoError(t, err)
+	assert.Equal(t, orders[1:], loaded.Orders)
+}
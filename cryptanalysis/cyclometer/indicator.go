@@ -0,0 +1,208 @@
+// Package cyclometer reproduces the method used by Marian Rejewski and the Polish Cipher Bureau to find the daily
+// key from the doubled indicators used until 1938.
+//
+// Every message started with the message key typed twice, encoded with the daily Grundstellung. So, the first and
+// the fourth letters of every indicator are the same letter encoded at positions 1 and 4, and the same holds for the
+// second and fifth, and for the third and sixth letters. With enough indicators, the permutations that take the first
+// letter into the fourth one (AD), the second into the fifth (BE) and the third into the sixth (CF) are completely
+// known. The lengths of their cycles (the characteristic) do not depend on the plugboard, and can be looked up in a
+// catalog of every rotor order and position, like the one the Polish built with the cyclometer.
+package cyclometer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Indicator is an encoded doubled message key, with the letters from 0 ('A') to 25 ('Z').
+type Indicator [6]byte
+
+// ParseIndicator reads an indicator of 6 letters. Spaces are ignored, and case does not matter.
+func ParseIndicator(s string) (Indicator, error) {
+	var i Indicator
+	n := 0
+
+	for _, r := range strings.ToUpper(s) {
+		if r == ' ' {
+			continue
+		}
+
+		if r < 'A' || r > 'Z' || n == 6 {
+			return i, fmt.Errorf("invalid indicator '%s': should have 6 letters", s)
+		}
+
+		i[n] = byte(r - 'A')
+		n++
+	}
+
+	if n != 6 {
+		return i, fmt.Errorf("invalid indicator '%s': should have 6 letters", s)
+	}
+
+	return i, nil
+}
+
+// ParseIndicators reads every indicator in s, separated by spaces, commas or new lines.
+func ParseIndicators(s string) ([]Indicator, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' || r == '\n' || r == '\r' || r == '\t' })
+	indicators := make([]Indicator, 0, len(fields))
+
+	for _, f := range fields {
+		i, err := ParseIndicator(f)
+		if err != nil {
+			return nil, err
+		}
+
+		indicators = append(indicators, i)
+	}
+
+	return indicators, nil
+}
+
+func (i Indicator) String() string {
+	b := make([]byte, 6)
+
+	for n, c := range i {
+		b[n] = 'A' + c
+	}
+
+	return string(b)
+}
+
+// Female reports whether the same letter appears in the positions n and n+3 (n from 0 to 2). This happens when the
+// permutation at position n+3 maps the plain letter to the same letter as the one at position n.
+func (i Indicator) Female(n int) bool {
+	return i[n] == i[n+3]
+}
+
+// Characteristic is the cycle structure of the permutations AD, BE and CF: the length of each cycle, from the
+// largest to the smallest. Since the permutations are the product of two involutions, the cycles always come in
+// pairs of the same length.
+type Characteristic [3][]int
+
+// String returns the characteristic in the usual notation, like "13 13 | 10 10 3 3 | 9 9 2 2 1 1 1 1".
+func (c Characteristic) String() string {
+	parts := make([]string, 3)
+
+	for p, lengths := range c {
+		s := make([]string, len(lengths))
+		for i, l := range lengths {
+			s[i] = strconv.Itoa(l)
+		}
+
+		parts[p] = strings.Join(s, " ")
+	}
+
+	return strings.Join(parts, " | ")
+}
+
+// ParseCharacteristic reads a characteristic in the notation returned by String.
+func ParseCharacteristic(s string) (Characteristic, error) {
+	var c Characteristic
+	parts := strings.Split(s, "|")
+
+	if len(parts) != 3 {
+		return c, errors.New("the characteristic should have 3 parts separated by '|'")
+	}
+
+	for p, part := range parts {
+		sum := 0
+
+		for _, f := range strings.Fields(part) {
+			n, err := strconv.Atoi(f)
+			if err != nil || n <= 0 {
+				return c, fmt.Errorf("invalid cycle length '%s'", f)
+			}
+
+			c[p] = append(c[p], n)
+			sum += n
+		}
+
+		if sum != 26 {
+			return c, fmt.Errorf("the cycle lengths of part %d should add up to 26", p+1)
+		}
+
+		sort.Sort(sort.Reverse(sort.IntSlice(c[p])))
+	}
+
+	return c, nil
+}
+
+// FromIndicators computes the characteristic of a day from its indicators. The indicators should be enough to
+// determine the permutations AD, BE and CF completely (usually, about 60 to 80 indicators).
+func FromIndicators(indicators []Indicator) (Characteristic, error) {
+	var c Characteristic
+
+	for p := 0; p < 3; p++ {
+		var perm, source [26]byte
+		var known, used [26]bool
+
+		for _, i := range indicators {
+			from, to := i[p], i[p+3]
+
+			if known[from] && perm[from] != to {
+				return c, fmt.Errorf("the indicators are inconsistent: '%c' goes to both '%c' and '%c' in %s",
+					'A'+from, 'A'+perm[from], 'A'+to, pairName(p))
+			}
+
+			if used[to] && source[to] != from {
+				return c, fmt.Errorf("the indicators are inconsistent: both '%c' and '%c' go to '%c' in %s",
+					'A'+source[to], 'A'+from, 'A'+to, pairName(p))
+			}
+
+			perm[from], known[from] = to, true
+			source[to], used[to] = from, true
+		}
+
+		missing := []byte{}
+		for x, k := range known {
+			if !k {
+				missing = append(missing, byte('A'+x))
+			}
+		}
+
+		if len(missing) > 0 {
+			return c, fmt.Errorf("not enough indicators to determine %s: missing %s", pairName(p), missing)
+		}
+
+		c[p] = cycles(&perm)
+
+		// the product of two reciprocal permutations has its cycles in pairs of the same length
+		for i := 0; i < len(c[p]); i += 2 {
+			if i+1 == len(c[p]) || c[p][i] != c[p][i+1] {
+				return c, fmt.Errorf("the cycles of %s do not come in pairs, so the indicators were not made with the same key", pairName(p))
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// pairName returns the name of the permutation p: AD, BE or CF
+func pairName(p int) string {
+	return string([]byte{byte('A' + p), byte('D' + p)})
+}
+
+// cycles returns the lengths of the cycles of a permutation, from the largest to the smallest
+func cycles(perm *[26]byte) []int {
+	var seen [26]bool
+	lengths := []int{}
+
+	for x := range perm {
+		n := 0
+		for y := x; !seen[y]; y = int(perm[y]) {
+			seen[y] = true
+			n++
+		}
+
+		if n > 0 {
+			lengths = append(lengths, n)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(lengths)))
+	return lengths
+}
@@ -0,0 +1,117 @@
+package cyclometer_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/cyclometer"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+// indicators encodes random doubled message keys with the daily key
+func indicators(t *testing.T, daily enigma.Settings, n int) []cyclometer.Indicator {
+	e, err := enigma.WithSettings(daily)
+	assert.NoError(t, err)
+
+	rng := rand.New(rand.NewSource(1938))
+	result := make([]cyclometer.Indicator, n)
+
+	for i := range result {
+		key := []byte{byte('A' + rng.Intn(26)), byte('A' + rng.Intn(26)), byte('A' + rng.Intn(26))}
+		e.SetWindow(daily.Window)
+
+		indicator, err := cyclometer.ParseIndicator(e.EncodeMessage(string(key)+string(key), 0))
+		assert.NoError(t, err)
+		result[i] = indicator
+	}
+
+	return result
+}
+
+func TestParseIndicator(t *testing.T) {
+	i, err := cyclometer.ParseIndicator("dmq vbn")
+	assert.NoError(t, err)
+	assert.Equal(t, cyclometer.Indicator{3, 12, 16, 21, 1, 13}, i)
+	assert.Equal(t, "DMQVBN", i.String())
+
+	for _, s := range []string{"DMQVB", "DMQVBNA", "DMQVB1"} {
+		_, err = cyclometer.ParseIndicator(s)
+		assert.EqualError(t, err, "invalid indicator '"+s+"': should have 6 letters")
+	}
+
+	list, err := cyclometer.ParseIndicators("DMQVBN, VONPUY\nPUCFMQ")
+	assert.NoError(t, err)
+	assert.Len(t, list, 3)
+
+	_, err = cyclometer.ParseIndicators("DMQVBN VON")
+	assert.Error(t, err)
+}
+
+func TestFemale(t *testing.T) {
+	i, _ := cyclometer.ParseIndicator("ABCAXY")
+	assert.True(t, i.Female(0))
+	assert.False(t, i.Female(1))
+	assert.False(t, i.Female(2))
+}
+
+func TestCharacteristic(t *testing.T) {
+	c, err := cyclometer.ParseCharacteristic("13 13 | 3 10 10 3 | 9 9 2 2 1 1 1 1")
+	assert.NoError(t, err)
+	assert.Equal(t, cyclometer.Characteristic{{13, 13}, {10, 10, 3, 3}, {9, 9, 2, 2, 1, 1, 1, 1}}, c)
+	assert.Equal(t, "13 13 | 10 10 3 3 | 9 9 2 2 1 1 1 1", c.String())
+
+	var tests = []struct {
+		value    string
+		expected string
+	}{
+		{"13 13 | 13 13", "the characteristic should have 3 parts separated by '|'"},
+		{"13 13 | 13 13 | 13 x", "invalid cycle length 'x'"},
+		{"13 13 | 13 12 | 13 13", "the cycle lengths of part 2 should add up to 26"},
+	}
+
+	for _, test := range tests {
+		_, err := cyclometer.ParseCharacteristic(test.value)
+		assert.EqualError(t, err, test.expected)
+	}
+}
+
+func TestFromIndicators(t *testing.T) {
+	daily := enigma.Settings{Reflector: "B", Slow: "II", Middle: "I", Fast: "III", Window: "KDR", Plugs: "AQBNCYEOFZ"}
+	c, err := cyclometer.FromIndicators(indicators(t, daily, 150))
+	assert.NoError(t, err)
+
+	for _, lengths := range c {
+		sum := 0
+		for i, l := range lengths {
+			sum += l
+			if i%2 == 1 {
+				assert.Equal(t, lengths[i-1], l)
+			}
+		}
+
+		assert.Equal(t, 26, sum)
+	}
+
+	_, err = cyclometer.FromIndicators(indicators(t, daily, 3))
+	assert.Contains(t, err.Error(), "not enough indicators to determine AD")
+
+	a, _ := cyclometer.ParseIndicator("ABCDEF")
+	b, _ := cyclometer.ParseIndicator("AXYZWV")
+	_, err = cyclometer.FromIndicators([]cyclometer.Indicator{a, b})
+	assert.EqualError(t, err, "the indicators are inconsistent: 'A' goes to both 'D' and 'Z' in AD")
+
+	b, _ = cyclometer.ParseIndicator("BXYDWV")
+	_, err = cyclometer.FromIndicators([]cyclometer.Indicator{a, b})
+	assert.EqualError(t, err, "the indicators are inconsistent: both 'A' and 'B' go to 'D' in AD")
+
+	// a single cycle of 26 letters cannot be made by an enigma
+	shifted := []cyclometer.Indicator{}
+	for x := 0; x < 26; x++ {
+		y := (x + 1) % 26
+		i, _ := cyclometer.ParseIndicator(string([]byte{byte('A' + x), byte('A' + x), byte('A' + x), byte('A' + y), byte('A' + y), byte('A' + y)}))
+		shifted = append(shifted, i)
+	}
+	_, err = cyclometer.FromIndicators(shifted)
+	assert.EqualError(t, err, "the cycles of AD do not come in pairs, so the indicators were not made with the same key")
+}
@@ -0,0 +1,147 @@
+package plugboard
+
+// start is a plugboard to be completed by hill climbing, with the letters whose plugs are already known
+type start struct {
+	plugs *[26]byte
+	fixed [26]bool
+}
+
+// constraint is a keypress of the crib: the plug of the cipher letter is the encoding of the plug of the plain letter
+type constraint struct {
+	plain, cipher byte
+	perm          *[26]byte
+}
+
+// steckers is a partial plugboard, with -1 for the letters whose plug is unknown
+type steckers [26]int
+
+// deduce finds the plugboards of the crib letters that are consistent with every keypress of the crib, testing each
+// hypothesis for the plug of one letter and propagating its consequences, as the bombe does
+func deduce(cipher, crib []byte, perms [][26]byte, maxPlugs int) []start {
+	constraints := make([]constraint, len(crib))
+	for i, c := range crib {
+		constraints[i] = constraint{plain: c, cipher: cipher[i], perm: &perms[i]}
+	}
+
+	var s steckers
+	for i := range s {
+		s[i] = -1
+	}
+
+	d := &deduction{constraints: constraints, maxPlugs: maxPlugs}
+	d.search(s)
+
+	return d.solutions
+}
+
+type deduction struct {
+	constraints []constraint
+	maxPlugs    int
+	solutions   []start
+}
+
+func (d *deduction) search(s steckers) {
+	if len(d.solutions) >= maxSolutions {
+		return
+	}
+
+	letter := d.next(&s)
+	if letter == -1 {
+		d.solutions = append(d.solutions, s.start())
+		return
+	}
+
+	for plug := 0; plug < 26; plug++ {
+		trial := s
+		if trial.set(letter, plug) && d.propagate(&trial) {
+			d.search(trial)
+		}
+	}
+}
+
+// next returns the crib letter with unknown plug that appears in most keypresses, or -1 if every plug is known
+func (d *deduction) next(s *steckers) int {
+	var counts [26]int
+
+	for _, c := range d.constraints {
+		counts[c.plain]++
+		counts[c.cipher]++
+	}
+
+	best := -1
+	for x, n := range counts {
+		if n > 0 && s[x] == -1 && (best == -1 || n > counts[best]) {
+			best = x
+		}
+	}
+
+	return best
+}
+
+// propagate applies the constraints until no new plug is found. It returns false on a contradiction.
+func (d *deduction) propagate(s *steckers) bool {
+	for changed := true; changed; {
+		changed = false
+
+		for _, c := range d.constraints {
+			p, q := s[c.plain], s[c.cipher]
+
+			switch {
+			case p >= 0 && q == -1:
+				if !s.set(int(c.cipher), int(c.perm[p])) {
+					return false
+				}
+				changed = true
+			case p == -1 && q >= 0:
+				if !s.set(int(c.plain), int(c.perm[q])) {
+					return false
+				}
+				changed = true
+			case p >= 0 && q >= 0 && int(c.perm[p]) != q:
+				return false
+			}
+		}
+	}
+
+	return s.count() <= d.maxPlugs
+}
+
+// set plugs a to b, returning false if this contradicts the known plugs
+func (s *steckers) set(a, b int) bool {
+	if s[a] == b {
+		return true
+	}
+
+	if s[a] != -1 || s[b] != -1 {
+		return false
+	}
+
+	s[a], s[b] = b, a
+	return true
+}
+
+func (s *steckers) count() int {
+	n := 0
+
+	for x, p := range s {
+		if p > x {
+			n++
+		}
+	}
+
+	return n
+}
+
+// start returns the plugboard with the known plugs; the other letters are not plugged
+func (s *steckers) start() start {
+	result := start{plugs: identity()}
+
+	for x, p := range s {
+		if p >= 0 {
+			result.plugs[x] = byte(p)
+			result.fixed[x] = true
+		}
+	}
+
+	return result
+}
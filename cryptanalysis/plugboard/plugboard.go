@@ -0,0 +1,331 @@
+// Package plugboard recovers the plugboard (the "steckers") of a message when the rotor order, the ring settings and
+// the start position are already known, as in the final step of a bombe stop or of a ciphertext-only attack.
+//
+// With a crib (a piece of known plaintext), the plugboard is deduced by constraint propagation: each hypothesis for
+// the stecker of a crib letter implies the steckers of the letters linked to it, until every crib letter is known or a
+// contradiction is found. The letters that are not in the crib, or every letter when there is no crib, are recovered
+// by hill climbing on the scores of the decoded text.
+package plugboard
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/scoring"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/ibraimgm/enigma/machine/parts"
+)
+
+// maximum number of plugboards consistent with the crib that are kept for the hill climbing
+const maxSolutions = 1000
+
+// number of the best plugboards consistent with the crib that are completed by hill climbing
+const climbed = 5
+
+// Options controls the recovery.
+type Options struct {
+	// Crib is a piece of known plaintext. Empty means there is no crib.
+	Crib string
+	// Offset is the position of the crib in the letters of the ciphertext.
+	Offset int
+	// MaxPlugs is the maximum number of plug pairs. Zero means 10.
+	MaxPlugs int
+	// Language of the plaintext, used by the default scorer and to compute the confidence. Empty means German.
+	Language scoring.Language
+	// Scorer used in the hill climbing. Nil means the bigrams of the language.
+	Scorer scoring.Scorer
+}
+
+// Result is the most likely plugboard found.
+type Result struct {
+	// Plugs are the plug pairs found (ex: "ABCD" means A-B and C-D).
+	Plugs string
+	// Plugboard is the plugboard of the plug pairs.
+	Plugboard parts.Plugboard
+	// Plaintext is the ciphertext decoded with the plugboard.
+	Plaintext string
+	// Score is the score of the plaintext.
+	Score float64
+	// Confidence is how close the index of coincidence of the plaintext is to the one of the language, from 0
+	// (random text) to 1.
+	Confidence float64
+}
+
+// Recover finds the most likely plugboard of the ciphertext, encoded with the machine from its current window.
+// The machine should be built by the enigma package, without a plugboard, and is not changed. Only the letters of
+// the ciphertext and of the crib are considered.
+func Recover(e enigma.Enigma, ciphertext string, options Options) (Result, error) {
+	options, err := withDefaults(options)
+	if err != nil {
+		return Result{}, err
+	}
+
+	plugs, err := enigma.Plugs(e)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if plugs != "" {
+		return Result{}, fmt.Errorf("the machine should not have a plugboard (it has %s)", plugs)
+	}
+
+	// the permutations are read from a copy, so the window and the keypresses of e are kept
+	c, err := enigma.Compile(e)
+	if err != nil {
+		return Result{}, err
+	}
+
+	cipher := scoring.Letters(ciphertext)
+	crib := scoring.Letters(options.Crib)
+
+	if len(cipher) < 2 {
+		return Result{}, errors.New("the ciphertext should have at least 2 letters")
+	}
+
+	if options.Offset < 0 || options.Offset+len(crib) > len(cipher) {
+		return Result{}, fmt.Errorf("the crib does not fit in the ciphertext at offset %d", options.Offset)
+	}
+
+	for i, c := range crib {
+		if cipher[options.Offset+i] == c {
+			return Result{}, fmt.Errorf("the crib letter '%c' at %d is encoded to itself", 'A'+c, options.Offset+i)
+		}
+	}
+
+	r := &recovery{
+		cipher:  cipher,
+		perms:   permutations(c, len(cipher)),
+		plain:   make([]byte, len(cipher)),
+		options: options,
+	}
+	starts := []start{{plugs: identity()}}
+
+	if len(crib) > 0 {
+		starts = deduce(cipher[options.Offset:], crib, r.perms[options.Offset:], options.MaxPlugs)
+		if len(starts) == 0 {
+			return Result{}, errors.New("no plugboard is consistent with the crib")
+		}
+
+		r.sortByScore(starts)
+		if len(starts) > climbed {
+			starts = starts[:climbed]
+		}
+	}
+
+	best := starts[0].plugs
+	bestScore := 0.0
+
+	for i, s := range starts {
+		r.climb(s.plugs, &s.fixed)
+
+		if score := r.score(options.Scorer, s.plugs); i == 0 || score > bestScore {
+			best, bestScore = s.plugs, score
+		}
+	}
+
+	return r.result(best)
+}
+
+func withDefaults(options Options) (Options, error) {
+	if options.MaxPlugs <= 0 {
+		options.MaxPlugs = 10
+	}
+
+	if options.Language == "" {
+		options.Language = scoring.German
+	}
+
+	if options.Scorer == nil {
+		bigrams, err := scoring.Builtin(options.Language, 2)
+		if err != nil {
+			return options, err
+		}
+
+		options.Scorer = bigrams
+	}
+
+	return options, nil
+}
+
+// permutations returns the encoding of every letter, for each keypress of the message. The machine is moved.
+func permutations(e enigma.Enigma, size int) [][26]byte {
+	// from here, SeekTo(i) moves the rotors to the i-th keypress of the message
+	e.SetWindow(e.Window())
+
+	perms := make([][26]byte, size)
+
+	for i := range perms {
+		for x := 0; x < 26; x++ {
+			e.SeekTo(uint64(i))
+			c, _ := e.Encode(rune('A' + x))
+			perms[i][x] = byte(c - 'A')
+		}
+	}
+
+	return perms
+}
+
+func identity() *[26]byte {
+	var plugs [26]byte
+
+	for i := range plugs {
+		plugs[i] = byte(i)
+	}
+
+	return &plugs
+}
+
+type recovery struct {
+	cipher  []byte
+	perms   [][26]byte
+	plain   []byte
+	options Options
+}
+
+// decode fills the plaintext with the ciphertext decoded with the plugs
+func (r *recovery) decode(plugs *[26]byte) {
+	for i, c := range r.cipher {
+		r.plain[i] = plugs[r.perms[i][plugs[c]]]
+	}
+}
+
+func (r *recovery) score(s scoring.Scorer, plugs *[26]byte) float64 {
+	r.decode(plugs)
+	return s.Score(r.plain)
+}
+
+// climb improves the plugs by hill climbing, first with the index of coincidence and then with the scorer.
+// Only the letters that are not fixed by the crib are changed.
+func (r *recovery) climb(plugs *[26]byte, fixed *[26]bool) {
+	for _, s := range []scoring.Scorer{scoring.IoC, r.options.Scorer} {
+		best := r.score(s, plugs)
+
+		for improved := true; improved; {
+			improved = false
+
+			for a := 0; a < 26; a++ {
+				for b := a + 1; b < 26; b++ {
+					if fixed[a] || fixed[b] {
+						continue
+					}
+
+					trial := *plugs
+					swapPlug(&trial, a, b)
+					if countPlugs(&trial) > r.options.MaxPlugs {
+						continue
+					}
+
+					if v := r.score(s, &trial); v > best {
+						*plugs, best, improved = trial, v, true
+					}
+				}
+			}
+		}
+	}
+}
+
+func (r *recovery) result(plugs *[26]byte) (Result, error) {
+	frequencies, err := scoring.Frequencies(r.options.Language)
+	if err != nil {
+		return Result{}, err
+	}
+
+	expected := 0.0
+	for _, f := range frequencies {
+		expected += f * f
+	}
+
+	score := r.score(r.options.Scorer, plugs)
+	confidence := (scoring.IoC.Score(r.plain) - 1.0/26) / (expected - 1.0/26)
+	if confidence < 0 {
+		confidence = 0
+	} else if confidence > 1 {
+		confidence = 1
+	}
+
+	result := Result{
+		Plugs:      plugsToString(plugs),
+		Plaintext:  toLetters(r.plain),
+		Score:      score,
+		Confidence: confidence,
+	}
+
+	result.Plugboard = parts.CreatePlugboard(result.Plugs)
+	return result, nil
+}
+
+// swapPlug connects a to b, disconnecting their current plugs. If a is already connected to b, they are
+// disconnected instead.
+func swapPlug(plugs *[26]byte, a, b int) {
+	if int(plugs[a]) == b {
+		plugs[a], plugs[b] = byte(a), byte(b)
+		return
+	}
+
+	for _, x := range []int{a, b} {
+		other := plugs[x]
+		plugs[other] = other
+		plugs[x] = byte(x)
+	}
+
+	plugs[a], plugs[b] = byte(b), byte(a)
+}
+
+func countPlugs(plugs *[26]byte) int {
+	n := 0
+
+	for i, p := range plugs {
+		if int(p) > i {
+			n++
+		}
+	}
+
+	return n
+}
+
+func plugsToString(plugs *[26]byte) string {
+	var sb strings.Builder
+
+	for i, p := range plugs {
+		if int(p) > i {
+			sb.WriteByte(byte('A' + i))
+			sb.WriteByte('A' + p)
+		}
+	}
+
+	return sb.String()
+}
+
+func toLetters(signals []byte) string {
+	letters := make([]byte, len(signals))
+
+	for i, s := range signals {
+		letters[i] = 'A' + s
+	}
+
+	return string(letters)
+}
+
+// sortByScore sorts the plugboards by the score of the text decoded with them, best first
+func (r *recovery) sortByScore(starts []start) {
+	scores := make([]float64, len(starts))
+	for i := range starts {
+		scores[i] = r.score(r.options.Scorer, starts[i].plugs)
+	}
+
+	sort.Sort(byScore{starts, scores})
+}
+
+type byScore struct {
+	starts []start
+	scores []float64
+}
+
+func (b byScore) Len() int           { return len(b.starts) }
+func (b byScore) Less(i, j int) bool { return b.scores[i] > b.scores[j] }
+func (b byScore) Swap(i, j int) {
+	b.starts[i], b.starts[j] = b.starts[j], b.starts[i]
+	b.scores[i], b.scores[j] = b.scores[j], b.scores[i]
+}
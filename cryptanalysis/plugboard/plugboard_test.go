@@ -0,0 +1,134 @@
+package plugboard_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/plugboard"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/ibraimgm/enigma/machine/parts"
+	"github.com/ibraimgm/enigma/text"
+	"github.com/stretchr/testify/assert"
+)
+
+var settings = enigma.Settings{
+	Reflector: "B",
+	Slow:      "II",
+	Middle:    "V",
+	Fast:      "III",
+	Ring:      "AMF",
+	Window:    "QDR",
+	Plugs:     "AVBSCGDLFUHZINKMOXPR",
+}
+
+// german returns n letters of German text
+func german(t *testing.T, n int) string {
+	corpus, err := os.ReadFile("../scoring/corpus/german.txt")
+	assert.NoError(t, err)
+
+	return text.Conventions["heer"].Prepare(string(corpus))[:n]
+}
+
+// encode returns the message encoded with the settings, and a machine without plugboard in the same position
+func encode(t *testing.T, message string) (string, enigma.Enigma) {
+	e, err := enigma.WithSettings(settings)
+	assert.NoError(t, err)
+
+	unplugged := settings
+	unplugged.Plugs = ""
+	u, err := enigma.WithSettings(unplugged)
+	assert.NoError(t, err)
+
+	return e.EncodeMessage(message, 0), u
+}
+
+func TestRecoverWithCrib(t *testing.T) {
+	plaintext := german(t, 150)
+	ciphertext, e := encode(t, plaintext)
+
+	result, err := plugboard.Recover(e, ciphertext, plugboard.Options{Crib: plaintext[20:50], Offset: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, settings.Plugs, result.Plugs)
+	assert.Equal(t, plaintext, result.Plaintext)
+	assert.True(t, result.Confidence > 0.6)
+	assert.Equal(t, settings.Window, e.Window())
+
+	// the plugboard of the result works on a machine
+	e2 := enigma.Assemble(parts.DefaultKeyboard, result.Plugboard, mustRotor(t, "II"), mustRotor(t, "V"),
+		mustRotor(t, "III"), parts.Reflectors["B"], parts.DefaultLightboard)
+	assert.NoError(t, e2.Configure(settings.Ring, settings.Window))
+	assert.Equal(t, plaintext, e2.EncodeMessage(ciphertext, 0))
+}
+
+func TestRecoverKeepsMachine(t *testing.T) {
+	plaintext := german(t, 155)
+	ciphertext, e := encode(t, plaintext)
+
+	// the message starts at the 5th keypress of the machine
+	assert.NoError(t, e.SetWindow(settings.Window))
+	e.Advance(5)
+	window := e.Window()
+
+	result, err := plugboard.Recover(e, ciphertext[5:], plugboard.Options{Crib: plaintext[25:55], Offset: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext[5:], result.Plaintext)
+
+	assert.Equal(t, window, e.Window())
+	assert.True(t, e.Unstep())
+	e.SeekTo(0)
+	assert.Equal(t, settings.Window, e.Window())
+}
+
+func mustRotor(t *testing.T, id string) parts.Rotor {
+	r, err := parts.GetRotor(id)
+	assert.NoError(t, err)
+
+	return r
+}
+
+func TestRecoverWithoutCrib(t *testing.T) {
+	plaintext := german(t, 600)
+	ciphertext, e := encode(t, plaintext)
+
+	result, err := plugboard.Recover(e, ciphertext, plugboard.Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, settings.Plugs, result.Plugs)
+	assert.Equal(t, plaintext, result.Plaintext)
+	assert.True(t, result.Confidence > 0.6)
+
+	// with the wrong rotor positions, the text stays random
+	assert.NoError(t, e.SetWindow("AAA"))
+	result, err = plugboard.Recover(e, ciphertext, plugboard.Options{})
+	assert.NoError(t, err)
+	assert.True(t, result.Confidence < 0.5, "confidence: %v", result.Confidence)
+}
+
+func TestRecoverErrors(t *testing.T) {
+	e := enigma.WithDefaults()
+
+	_, err := plugboard.Recover(e, "A", plugboard.Options{})
+	assert.EqualError(t, err, "the ciphertext should have at least 2 letters")
+
+	_, err = plugboard.Recover(e, "ABCDEF", plugboard.Options{Crib: "XYZ", Offset: 4})
+	assert.EqualError(t, err, "the crib does not fit in the ciphertext at offset 4")
+
+	_, err = plugboard.Recover(e, "ABCDEF", plugboard.Options{Crib: "XYZ", Offset: -1})
+	assert.EqualError(t, err, "the crib does not fit in the ciphertext at offset -1")
+
+	_, err = plugboard.Recover(e, "ABCDEF", plugboard.Options{Crib: "XCZ", Offset: 1})
+	assert.EqualError(t, err, "the crib letter 'C' at 2 is encoded to itself")
+
+	_, err = plugboard.Recover(e, "ABCDEF", plugboard.Options{Language: "klingon"})
+	assert.EqualError(t, err, "no statistics for language 'klingon' with n = 2")
+
+	_, err = plugboard.Recover(e, "ABCDEFGHIJ", plugboard.Options{Crib: "ZZZZZZZZZZ", MaxPlugs: 1})
+	assert.EqualError(t, err, "no plugboard is consistent with the crib")
+
+	plugged, _ := enigma.WithSettings(settings)
+	_, err = plugboard.Recover(plugged, "ABCDEF", plugboard.Options{})
+	assert.EqualError(t, err, "the machine should not have a plugboard (it has AVBSCGDLFUHZINKMOXPR)")
+
+	_, err = plugboard.Recover(enigma.Assemble(parts.DefaultKeyboard, parts.NoPlugboard, mustRotor(t, "I"), mustRotor(t, "II"),
+		mustRotor(t, "III"), parts.Reflectors["B"], parts.DefaultLightboard), "ABCDEF", plugboard.Options{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,206 @@
+//go:build ignore
+
+// This program generates the n-gram statistics embedded in the scoring package. The texts used are:
+//
+//   - the German and English paragraphs of the web pages used as test files by go-trafilatura (the module
+//     github.com/markusmobius/go-trafilatura, downloaded with 'go mod download'), about 3.8 MB of German and 1 MB of
+//     English text from 700 sites;
+//   - "Opticks", by Isaac Newton, from the test data of the Go distribution;
+//   - the texts in the corpus directory.
+//
+// Only the counts are kept in the data directory. Punctuation and digits are removed before the German text is
+// prepared with the 'heer' convention, so the statistics have the letters typed by an operator for the words, but
+// not the replacements of the punctuation (like 'ZZ' for a comma), that depend on the writing style of the text.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ibraimgm/enigma/text"
+)
+
+// module with the web pages, pinned so the statistics can be generated again
+const pages = "github.com/markusmobius/go-trafilatura@v1.12.2"
+
+// the most common words of each language, used to find the language of a paragraph
+var commonWords = map[string][]string{
+	"german": strings.Fields("der die das und ist nicht ein eine zu den von mit sich des auf für im dem es auch als an " +
+		"er wie bei noch wird aus nach sie wir ich werden hat sind aber oder um wenn so nur vor über einer"),
+	"english": strings.Fields("the and of to in is that it for was with as on be by this are at from have not or " +
+		"which but an"),
+}
+
+var (
+	paragraphRegexp = regexp.MustCompile(`(?is)<p\b[^>]*>(.*?)</p>`)
+	skipRegexp      = regexp.MustCompile(`(?is)<(script|style|noscript|svg|head)\b.*?</(script|style|noscript|svg|head)>`)
+	tagRegexp       = regexp.MustCompile(`<[^>]+>`)
+	wordRegexp      = regexp.MustCompile(`[a-zäöüß]+`)
+)
+
+func main() {
+	languages := map[string]func(string) string{
+		"german":  prepareGerman,
+		"english": text.Letters,
+	}
+
+	paragraphs, err := webParagraphs()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opticks, err := os.ReadFile(filepath.Join(runtime.GOROOT(), "src", "testdata", "Isaac.Newton-Opticks.txt"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	paragraphs["english"] = append(paragraphs["english"], string(opticks))
+
+	for language, prepare := range languages {
+		corpus, err := os.ReadFile(filepath.Join("corpus", language+".txt"))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		prepared := prepare(strings.Join(append(paragraphs[language], string(corpus)), "\n"))
+		log.Printf("%s: %d letters", language, len(prepared))
+
+		for n := 1; n <= 4; n++ {
+			name := filepath.Join("data", fmt.Sprintf("%s_%d.txt", language, n))
+			if err := os.WriteFile(name, []byte(count(prepared, n)), 0644); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+// webParagraphs returns the paragraphs of the web pages of each language, without repetitions
+func webParagraphs() (map[string][]string, error) {
+	cmd := exec.Command("go", "mod", "download", "-json", pages)
+	cmd.Dir = os.TempDir()
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var module struct{ Dir string }
+	if err := json.Unmarshal(output, &module); err != nil {
+		return nil, err
+	}
+
+	names, err := filepath.Glob(filepath.Join(module.Dir, "test-files", "*", "*.html"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	seen := make(map[string]bool)
+	paragraphs := make(map[string][]string)
+
+	for _, name := range names {
+		page, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		body := skipRegexp.ReplaceAllString(string(page), " ")
+
+		for _, m := range paragraphRegexp.FindAllStringSubmatch(body, -1) {
+			p := strings.Join(strings.Fields(html.UnescapeString(tagRegexp.ReplaceAllString(m[1], " "))), " ")
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+
+			if language := languageOf(p); language != "" {
+				paragraphs[language] = append(paragraphs[language], p)
+			}
+		}
+	}
+
+	return paragraphs, nil
+}
+
+// languageOf returns the language of a paragraph, or an empty string if it is too short or the language is unknown
+func languageOf(p string) string {
+	words := wordRegexp.FindAllString(strings.ToLower(p), -1)
+	if len(words) < 8 {
+		return ""
+	}
+
+	share := make(map[string]float64)
+	for language, common := range commonWords {
+		n := 0
+		for _, w := range words {
+			for _, c := range common {
+				if w == c {
+					n++
+					break
+				}
+			}
+		}
+
+		share[language] = float64(n) / float64(len(words))
+	}
+
+	switch {
+	case share["german"] > 0.12 && share["german"] > 2*share["english"]:
+		return "german"
+	case share["english"] > 0.12 && share["english"] > 2*share["german"]:
+		return "english"
+	default:
+		return ""
+	}
+}
+
+// prepareGerman prepares the words of s with the 'heer' convention, without its punctuation and digits
+func prepareGerman(s string) string {
+	words := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return r
+		}
+
+		return ' '
+	}, s)
+
+	return text.Letters(text.Conventions["heer"].Prepare(words))
+}
+
+// count returns the n-grams of s and their counts, one per line, with the most frequent first
+func count(s string, n int) string {
+	counts := make(map[string]int)
+	for i := 0; i+n <= len(s); i++ {
+		counts[s[i:i+n]]++
+	}
+
+	ngrams := make([]string, 0, len(counts))
+	for ngram := range counts {
+		ngrams = append(ngrams, ngram)
+	}
+
+	sort.Slice(ngrams, func(i, j int) bool {
+		if counts[ngrams[i]] != counts[ngrams[j]] {
+			return counts[ngrams[i]] > counts[ngrams[j]]
+		}
+
+		return ngrams[i] < ngrams[j]
+	})
+
+	var sb strings.Builder
+	for _, ngram := range ngrams {
+		fmt.Fprintf(&sb, "%s %d\n", ngram, counts[ngram])
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,202 @@
+package scoring
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ibraimgm/enigma/machine/parts"
+)
+
+//go:generate go run gen.go
+
+//go:embed data/*.txt
+var data embed.FS
+
+// Language is the name of a language with embedded statistics.
+type Language string
+
+// Languages with embedded statistics, computed from about 3 million letters of German and 1.3 million letters of
+// English text (see gen.go). The German text was prepared with the 'heer' convention (see the text package), as it
+// would be typed in the enigma, but without its punctuation and digits.
+const (
+	German  Language = "german"
+	English Language = "english"
+)
+
+// NGram scores a text by the sum of the log-probabilities (in base 10) of each sequence of n letters.
+// N-grams never seen in the statistics get a probability lower than the rarest one.
+type NGram struct {
+	n    int
+	logs []float64
+}
+
+var (
+	builtinMutex sync.Mutex
+	builtinCache = make(map[string]*NGram)
+)
+
+// Builtin returns the embedded statistics of a language, for n-grams with n from 1 to 4 (letters, bigrams, trigrams
+// and quadgrams). The statistics are loaded once and shared by every caller.
+func Builtin(language Language, n int) (*NGram, error) {
+	name := fmt.Sprintf("data/%s_%d.txt", language, n)
+
+	builtinMutex.Lock()
+	defer builtinMutex.Unlock()
+
+	if g, ok := builtinCache[name]; ok {
+		return g, nil
+	}
+
+	f, err := data.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("no statistics for language '%s' with n = %d", language, n)
+	}
+	defer f.Close()
+
+	g, err := LoadNGrams(f)
+	if err != nil {
+		return nil, err
+	}
+
+	builtinCache[name] = g
+	return g, nil
+}
+
+// Frequencies returns the embedded letter frequencies of a language, from 'A' to 'Z', to use with NewChiSquared.
+func Frequencies(language Language) ([26]float64, error) {
+	var frequencies [26]float64
+
+	g, err := Builtin(language, 1)
+	if err != nil {
+		return frequencies, err
+	}
+
+	for i, l := range g.logs {
+		frequencies[i] = math.Pow(10, l)
+	}
+
+	return frequencies, nil
+}
+
+// LoadNGrams reads n-gram statistics, with an n-gram and its count in each line, separated by spaces
+// (ex: "ENDE 42"). Every n-gram should have the same size, from 1 to 4 letters. Empty lines and lines starting with
+// '#' are ignored.
+func LoadNGrams(r io.Reader) (*NGram, error) {
+	counts := make(map[string]float64)
+	n := 0
+	total := 0.0
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected an n-gram and a count", line)
+		}
+
+		ngram := strings.ToUpper(fields[0])
+		if n == 0 {
+			n = len(ngram)
+		}
+
+		if len(ngram) != n || n > 4 || strings.IndexFunc(ngram, func(r rune) bool { return r < 'A' || r > 'Z' }) != -1 {
+			return nil, fmt.Errorf("line %d: invalid n-gram '%s'", line, fields[0])
+		}
+
+		count, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || count < 0 {
+			return nil, fmt.Errorf("line %d: invalid count '%s'", line, fields[1])
+		}
+
+		counts[ngram] += count
+		total += count
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if total == 0 {
+		return nil, errors.New("the statistics should have at least one n-gram")
+	}
+
+	g := &NGram{n: n, logs: make([]float64, int(math.Pow(26, float64(n))))}
+	floor := math.Log10(0.01 / total)
+
+	for i := range g.logs {
+		g.logs[i] = floor
+	}
+
+	for ngram, count := range counts {
+		if count > 0 {
+			g.logs[index(ngram)] = math.Log10(count / total)
+		}
+	}
+
+	return g, nil
+}
+
+// LoadNGramsFile reads n-gram statistics from a file, in the format accepted by LoadNGrams.
+func LoadNGramsFile(name string) (*NGram, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadNGrams(f)
+}
+
+// index returns the position of an n-gram of uppercase letters in the table
+func index(ngram string) int {
+	i := 0
+
+	for _, c := range ngram {
+		i = i*26 + int(c-'A')
+	}
+
+	return i
+}
+
+// N returns the size of the n-grams.
+func (g *NGram) N() int {
+	return g.n
+}
+
+// Score returns the sum of the log-probabilities of the n-grams of a text of letters from 0 to 25.
+func (g *NGram) Score(text []byte) float64 {
+	return ngramScore(g, text, 0)
+}
+
+// ScoreSignals returns the sum of the log-probabilities of the n-grams of a text of signals from 1 to 26.
+func (g *NGram) ScoreSignals(signals []parts.Signal) float64 {
+	return ngramScore(g, signals, 1)
+}
+
+func ngramScore[T letter](g *NGram, text []T, base T) float64 {
+	size := len(g.logs)
+	score := 0.0
+	i := 0
+
+	for j, c := range text {
+		i = (i*26 + int(c-base)) % size
+
+		if j >= g.n-1 {
+			score += g.logs[i]
+		}
+	}
+
+	return score
+}
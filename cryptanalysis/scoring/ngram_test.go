@@ -0,0 +1,97 @@
+package scoring_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/scoring"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltin(t *testing.T) {
+	for _, language := range []scoring.Language{scoring.German, scoring.English} {
+		for n := 1; n <= 4; n++ {
+			g, err := scoring.Builtin(language, n)
+			assert.NoError(t, err)
+			assert.Equal(t, n, g.N())
+
+			same, _ := scoring.Builtin(language, n)
+			assert.True(t, g == same)
+		}
+	}
+
+	_, err := scoring.Builtin(scoring.German, 5)
+	assert.EqualError(t, err, "no statistics for language 'german' with n = 5")
+
+	_, err = scoring.Builtin("klingon", 2)
+	assert.EqualError(t, err, "no statistics for language 'klingon' with n = 2")
+}
+
+func TestBuiltinQuadgrams(t *testing.T) {
+	for _, language := range []scoring.Language{scoring.German, scoring.English} {
+		g, _ := scoring.Builtin(language, 4)
+
+		// the probability of an unseen quadgram is 0.01 / count, so more than a million quadgrams were counted
+		assert.True(t, g.Score(scoring.Letters("QQQQ")) < -8, "%s", language)
+	}
+
+	// the punctuation is not in the statistics, so 'ZZ' (a comma in the 'heer' convention) is rare
+	g, _ := scoring.Builtin(scoring.German, 4)
+	assert.True(t, g.Score(scoring.Letters("ENDE"))-g.Score(scoring.Letters("ENZZ")) > 2)
+}
+
+func TestBuiltinRanksLanguages(t *testing.T) {
+	for n := 2; n <= 4; n++ {
+		g, _ := scoring.Builtin(scoring.German, n)
+		assert.True(t, g.Score(scoring.Letters(german)) > g.Score(scoring.Letters(random)), "german, n = %d", n)
+
+		e, _ := scoring.Builtin(scoring.English, n)
+		assert.True(t, e.Score(scoring.Letters(english)) > e.Score(scoring.Letters(random)), "english, n = %d", n)
+		assert.True(t, e.Score(scoring.Letters(english)) > g.Score(scoring.Letters(english)), "english x german, n = %d", n)
+	}
+}
+
+func TestLoadNGrams(t *testing.T) {
+	g, err := scoring.LoadNGrams(strings.NewReader("# comment\nAB 3\n\nba 1\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, g.N())
+
+	assert.InDelta(t, math.Log10(0.75), g.Score(scoring.Letters("AB")), 1e-9)
+	assert.InDelta(t, math.Log10(0.75)+math.Log10(0.25), g.Score(scoring.Letters("ABA")), 1e-9)
+	assert.InDelta(t, math.Log10(0.01/4), g.Score(scoring.Letters("CD")), 1e-9)
+	assert.Equal(t, 0.0, g.Score(scoring.Letters("A")))
+	assert.Equal(t, g.Score(scoring.Letters("ABAB")), g.ScoreSignals(toSignals(scoring.Letters("ABAB"))))
+
+	var tests = []struct {
+		data     string
+		expected string
+	}{
+		{"", "the statistics should have at least one n-gram"},
+		{"AB", "line 1: expected an n-gram and a count"},
+		{"AB 1\nABC 1", "line 2: invalid n-gram 'ABC'"},
+		{"ABCDE 1", "line 1: invalid n-gram 'ABCDE'"},
+		{"A1 1", "line 1: invalid n-gram 'A1'"},
+		{"AB x", "line 1: invalid count 'x'"},
+		{"AB -1", "line 1: invalid count '-1'"},
+	}
+
+	for _, test := range tests {
+		_, err := scoring.LoadNGrams(strings.NewReader(test.data))
+		assert.EqualError(t, err, test.expected, "data: %q", test.data)
+	}
+}
+
+func TestLoadNGramsFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "trigrams.txt")
+	assert.NoError(t, os.WriteFile(name, []byte("THE 10\nAND 5\n"), 0644))
+
+	g, err := scoring.LoadNGramsFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, g.N())
+
+	_, err = scoring.LoadNGramsFile(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+// Package scoring rates how close a text is to a natural language, as needed by the automated attacks and by
+// plausibility checks of decoded messages.
+//
+// Every scorer works with texts of letters from 0 ('A') to 25 ('Z'), or with enigma signals from 1 ('A') to 26
+// ('Z'), and does not allocate memory, so it can be called millions of times from a search loop.
+package scoring
+
+import (
+	"github.com/ibraimgm/enigma/machine/parts"
+	"github.com/ibraimgm/enigma/text"
+)
+
+// Scorer rates a text. Higher scores mean a text closer to the expected language.
+type Scorer interface {
+	// Score rates a text of letters from 0 ('A') to 25 ('Z').
+	Score(text []byte) float64
+	// ScoreSignals rates a text of signals from 1 ('A') to 26 ('Z').
+	ScoreSignals(signals []parts.Signal) float64
+}
+
+// letter is a value that can be scored: a byte from 0 to 25 or a signal from 1 to 26
+type letter interface {
+	byte | parts.Signal
+}
+
+// IoC scores a text by its index of coincidence: the probability of two random letters of the text being equal.
+// It is around 0.038 for random text, 0.066 for English and 0.076 for German.
+var IoC Scorer = iocScorer{}
+
+type iocScorer struct{}
+
+func (iocScorer) Score(text []byte) float64 {
+	return ioc(text, 0)
+}
+
+func (iocScorer) ScoreSignals(signals []parts.Signal) float64 {
+	return ioc(signals, 1)
+}
+
+func ioc[T letter](text []T, base T) float64 {
+	var counts [26]int
+
+	for _, c := range text {
+		counts[c-base]++
+	}
+
+	size := len(text)
+	if size < 2 {
+		return 0
+	}
+
+	sum := 0
+	for _, n := range counts {
+		sum += n * (n - 1)
+	}
+
+	return float64(sum) / float64(size*(size-1))
+}
+
+// ChiSquared scores a text by how far its letter frequencies are from the expected ones, using the chi-squared
+// statistic. Since lower statistics mean a closer text, the score is the negative of the statistic.
+type ChiSquared struct {
+	expected [26]float64
+}
+
+// NewChiSquared returns a scorer with the expected frequency of each letter, from 'A' to 'Z'.
+// The frequencies do not need to add up to 1.
+func NewChiSquared(frequencies [26]float64) *ChiSquared {
+	c := &ChiSquared{}
+	total := 0.0
+
+	for _, f := range frequencies {
+		total += f
+	}
+
+	for i, f := range frequencies {
+		c.expected[i] = f / total
+		if c.expected[i] == 0 {
+			c.expected[i] = 0.0001
+		}
+	}
+
+	return c
+}
+
+// Score returns the negative chi-squared statistic of a text of letters from 0 to 25.
+func (c *ChiSquared) Score(text []byte) float64 {
+	return chiSquared(c, text, 0)
+}
+
+// ScoreSignals returns the negative chi-squared statistic of a text of signals from 1 to 26.
+func (c *ChiSquared) ScoreSignals(signals []parts.Signal) float64 {
+	return chiSquared(c, signals, 1)
+}
+
+func chiSquared[T letter](c *ChiSquared, text []T, base T) float64 {
+	var counts [26]int
+
+	for _, x := range text {
+		counts[x-base]++
+	}
+
+	size := float64(len(text))
+	sum := 0.0
+
+	for i, n := range counts {
+		expected := c.expected[i] * size
+		diff := float64(n) - expected
+		sum += diff * diff / expected
+	}
+
+	return -sum
+}
+
+// Letters returns the letters of s (see text.Letters) as values from 0 ('A') to 25 ('Z'), ignoring everything else.
+func Letters(s string) []byte {
+	values := []byte(text.Letters(s))
+
+	for i := range values {
+		values[i] -= 'A'
+	}
+
+	return values
+}
@@ -0,0 +1,79 @@
+package scoring_test
+
+import (
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/scoring"
+	"github.com/ibraimgm/enigma/machine/parts"
+	"github.com/stretchr/testify/assert"
+)
+
+const german = "DERKOMMANDANTMELDETZZDASSDASBOOTEINSATZBEREITISTUNDDIEBESATZUNGVOLLSTAENDIGANBORDIST"
+const english = "THECOMMANDERREPORTSTHATTHEBOATISREADYANDTHATTHECREWISCOMPLETEONBOARDTHESHIP"
+const random = "QXZVJKWPYQMFZXVBNQJLKWPZXCVMQJHGTRWQZPXKVBNMJQWZXPLKJHGFQWZXCVBNMPQWERTZUIOPX"
+
+func toSignals(text []byte) []parts.Signal {
+	signals := make([]parts.Signal, len(text))
+
+	for i, c := range text {
+		signals[i] = parts.Signal(c + 1)
+	}
+
+	return signals
+}
+
+func TestLetters(t *testing.T) {
+	assert.Equal(t, []byte{0, 1, 25}, scoring.Letters("a-b Z!"))
+	assert.Equal(t, []byte{}, scoring.Letters("123"))
+}
+
+func TestIoC(t *testing.T) {
+	var tests = []struct {
+		text     string
+		expected float64
+	}{
+		{"", 0},
+		{"A", 0},
+		{"AAAA", 1},
+		{"ABCD", 0},
+		{"AABB", 2.0 / 6.0},
+	}
+
+	for _, test := range tests {
+		text := scoring.Letters(test.text)
+		assert.InDelta(t, test.expected, scoring.IoC.Score(text), 1e-9, "text: %s", test.text)
+		assert.InDelta(t, test.expected, scoring.IoC.ScoreSignals(toSignals(text)), 1e-9, "text: %s", test.text)
+	}
+}
+
+func TestChiSquared(t *testing.T) {
+	frequencies, err := scoring.Frequencies(scoring.English)
+	assert.NoError(t, err)
+
+	c := scoring.NewChiSquared(frequencies)
+	assert.True(t, c.Score(scoring.Letters(english)) > c.Score(scoring.Letters(random)))
+	assert.Equal(t, c.Score(scoring.Letters(english)), c.ScoreSignals(toSignals(scoring.Letters(english))))
+
+	var uniform [26]float64
+	for i := range uniform {
+		uniform[i] = 1
+	}
+
+	c = scoring.NewChiSquared(uniform)
+	assert.InDelta(t, 0, c.Score(scoring.Letters("ABCDEFGHIJKLMNOPQRSTUVWXYZ")), 1e-9)
+	assert.InDelta(t, -650, c.Score(scoring.Letters("AAAAAAAAAAAAAAAAAAAAAAAAAA")), 1e-9)
+}
+
+func TestScoreDoesNotAllocate(t *testing.T) {
+	quadgrams, err := scoring.Builtin(scoring.German, 4)
+	assert.NoError(t, err)
+
+	frequencies, _ := scoring.Frequencies(scoring.German)
+	text := scoring.Letters(german)
+	signals := toSignals(text)
+
+	for _, s := range []scoring.Scorer{scoring.IoC, scoring.NewChiSquared(frequencies), quadgrams} {
+		assert.Equal(t, 0.0, testing.AllocsPerRun(100, func() { s.Score(text) }))
+		assert.Equal(t, 0.0, testing.AllocsPerRun(100, func() { s.ScoreSignals(signals) }))
+	}
+}
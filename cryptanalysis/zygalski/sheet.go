@@ -0,0 +1,190 @@
+// Package zygalski simulates the perforated sheets designed by Henryk Zygalski, used by the Polish Cipher Bureau
+// after September 1938 to find the rotor order and the ring setting from the females of the doubled indicators.
+//
+// When the Grundstellung was chosen by the operator and sent in the clear, the characteristics of the cyclometer
+// could no longer be used. But some positions of the machine can encode the same letter into the same letter three
+// keypresses later (a "female", like 'DMQ DXR'), and some cannot. Each sheet marks, for a rotor order and a position
+// of the slow rotor, the positions of the middle and fast rotors where this is possible; stacking the sheets of the
+// females of a day, shifted by their Grundstellungen, leaves only a few holes where the light passes through.
+//
+// As in the historical sheets, the turnover of the middle rotor during the indicator is not taken into account.
+package zygalski
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"github.com/ibraimgm/enigma/machine/parts"
+)
+
+// Sheet is a Zygalski sheet of a rotor order, for one position of the slow rotor. The positions are the ones of the
+// rotor cores (the window letter minus the ring setting), before the first letter of the indicator is encoded.
+type Sheet struct {
+	Reflector string
+	Slow      string
+	Middle    string
+	Fast      string
+	// Position of the slow rotor, from 0 ('A') to 25 ('Z').
+	Position byte
+	// Holes[m][f] is set when the middle rotor at m and the fast rotor at f allow a female in the letters 1 and 4.
+	Holes [26][26]bool
+}
+
+// Sheets returns the 26 sheets of a rotor order, one for each position of the slow rotor.
+func Sheets(reflector string, order [3]string) ([]*Sheet, error) {
+	holes, err := allHoles(reflector, order)
+	if err != nil {
+		return nil, err
+	}
+
+	sheets := make([]*Sheet, 26)
+	for s := range sheets {
+		sheets[s] = &Sheet{reflector, order[0], order[1], order[2], byte(s), holes[s]}
+	}
+
+	return sheets, nil
+}
+
+// allHoles computes the holes of every sheet of a rotor order: holes[s][m][f]
+func allHoles(reflector string, order [3]string) (*[26][26][26]bool, error) {
+	ref, ok := parts.Reflectors[reflector]
+	if !ok {
+		return nil, fmt.Errorf("unknown reflector: '%s'", reflector)
+	}
+
+	var rotors [3]parts.Rotor
+	for i, id := range order {
+		r, err := parts.GetRotor(id)
+		if err != nil {
+			return nil, err
+		}
+
+		rotors[i] = r
+	}
+
+	holes := &[26][26][26]bool{}
+	for s := 0; s < 26; s++ {
+		for m := 0; m < 26; m++ {
+			for f := 0; f < 26; f++ {
+				first := permutation(ref, rotors, s, m, f+1)
+				fourth := permutation(ref, rotors, s, m, f+4)
+
+				for x := range first {
+					if fourth[first[x]] == byte(x) {
+						holes[s][m][f] = true
+						break
+					}
+				}
+			}
+		}
+	}
+
+	return holes, nil
+}
+
+// permutation returns the encoding of every letter (from 0 to 25) with the rotor cores at the specified positions
+func permutation(ref parts.Reflector, rotors [3]parts.Rotor, s, m, f int) [26]byte {
+	var perm [26]byte
+
+	rotors[0].SetWindow(rune('A' + s%26))
+	rotors[1].SetWindow(rune('A' + m%26))
+	rotors[2].SetWindow(rune('A' + f%26))
+
+	for x := range perm {
+		signal := parts.Signal(x + 1)
+		signal = rotors[2].Scramble(signal)
+		signal = rotors[1].Scramble(signal)
+		signal = rotors[0].Scramble(signal)
+		signal = ref.Reflect(signal)
+		signal = rotors[0].Reverse(signal)
+		signal = rotors[1].Reverse(signal)
+		signal = rotors[2].Reverse(signal)
+		perm[x] = byte(signal - 1)
+	}
+
+	return perm
+}
+
+// Count returns the number of holes in the sheet.
+func (s *Sheet) Count() int {
+	n := 0
+
+	for m := range s.Holes {
+		for _, h := range s.Holes[m] {
+			if h {
+				n++
+			}
+		}
+	}
+
+	return n
+}
+
+// Image draws the sheet as in the historical ones: a grid of 51 by 51 squares (the 26 positions, with the first 25
+// repeated, so the sheets can be shifted over each other), where the holes are white and the rest is black. The
+// middle rotor positions go down, and the fast rotor positions go right. Each square has cellSize pixels.
+func (s *Sheet) Image(cellSize int) image.Image {
+	size := 51 * cellSize
+	img := image.NewGray(image.Rect(0, 0, size, size))
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if s.Holes[y/cellSize%26][x/cellSize%26] {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+
+	return img
+}
+
+// WritePNG writes the sheet image as a PNG file.
+func (s *Sheet) WritePNG(w io.Writer, cellSize int) error {
+	return png.Encode(w, s.Image(cellSize))
+}
+
+// WriteSVG writes the sheet as a SVG file, with the holes as white squares and the letters of the positions on the
+// borders.
+func (s *Sheet) WriteSVG(w io.Writer, cellSize int) error {
+	margin := cellSize
+	size := 51*cellSize + 2*margin
+	ew := &errWriter{w: w}
+
+	ew.printf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\">\n", size, size)
+	ew.printf("  <title>%s %s %s %s - %c</title>\n", s.Reflector, s.Slow, s.Middle, s.Fast, 'A'+s.Position)
+	ew.printf("  <rect width=\"%d\" height=\"%d\" fill=\"white\"/>\n", size, size)
+	ew.printf("  <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"black\"/>\n", margin, margin, 51*cellSize, 51*cellSize)
+
+	for i := 0; i < 51; i++ {
+		pos := margin + i*cellSize + cellSize/2
+		ew.printf("  <text x=\"%d\" y=\"%d\" font-size=\"%d\" text-anchor=\"middle\">%c</text>\n", pos, margin-2, cellSize-2, 'A'+i%26)
+		ew.printf("  <text x=\"%d\" y=\"%d\" font-size=\"%d\" text-anchor=\"middle\">%c</text>\n", margin/2, pos+cellSize/3, cellSize-2, 'A'+i%26)
+	}
+
+	for y := 0; y < 51; y++ {
+		for x := 0; x < 51; x++ {
+			if s.Holes[y%26][x%26] {
+				ew.printf("  <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"white\"/>\n",
+					margin+x*cellSize, margin+y*cellSize, cellSize, cellSize)
+			}
+		}
+	}
+
+	ew.printf("</svg>\n")
+	return ew.err
+}
+
+// errWriter keeps the first error, so the caller can check it only once
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, args...)
+	}
+}
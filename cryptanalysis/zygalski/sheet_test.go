@@ -0,0 +1,72 @@
+package zygalski_test
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/zygalski"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSheets(t *testing.T) {
+	sheets, err := zygalski.Sheets("B", [3]string{"I", "II", "III"})
+	assert.NoError(t, err)
+	assert.Len(t, sheets, 26)
+
+	for i, s := range sheets {
+		assert.Equal(t, byte(i), s.Position)
+		assert.True(t, s.Count() > 100 && s.Count() < 400, "holes: %d", s.Count())
+	}
+
+	_, err = zygalski.Sheets("X", [3]string{"I", "II", "III"})
+	assert.EqualError(t, err, "unknown reflector: 'X'")
+
+	_, err = zygalski.Sheets("B", [3]string{"I", "II", "X"})
+	assert.Error(t, err)
+}
+
+func TestSheetOutput(t *testing.T) {
+	sheets, _ := zygalski.Sheets("B", [3]string{"I", "II", "III"})
+	s := sheets[0]
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.WritePNG(&buf, 4))
+
+	img, err := png.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 51*4, img.Bounds().Dx())
+
+	for m := 0; m < 26; m++ {
+		for f := 0; f < 26; f++ {
+			r, _, _, _ := img.At(f*4+1, m*4+1).RGBA()
+			r2, _, _, _ := img.At((f+26)%51*4+1, m*4+1).RGBA()
+			assert.Equal(t, s.Holes[m][f], r > 0)
+			if f+26 < 51 {
+				assert.Equal(t, r, r2)
+			}
+		}
+	}
+
+	buf.Reset()
+	assert.NoError(t, s.WriteSVG(&buf, 10))
+	svg := buf.String()
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.Contains(t, svg, "<title>B I II III - A</title>")
+	assert.Equal(t, 1+countHoles(s), strings.Count(svg, "fill=\"white\""))
+}
+
+func countHoles(s *zygalski.Sheet) int {
+	n := 0
+
+	for y := 0; y < 51; y++ {
+		for x := 0; x < 51; x++ {
+			if s.Holes[y%26][x%26] {
+				n++
+			}
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,107 @@
+package zygalski
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/cyclometer"
+)
+
+// Message is the start of an intercepted message: the Grundstellung sent in the clear, and the doubled message key
+// encoded with it.
+type Message struct {
+	Grundstellung string
+	Indicator     cyclometer.Indicator
+}
+
+// ParseMessage reads a message in the form "GRUNDSTELLUNG INDICATOR" (ex: "RTJ WAHWIK").
+func ParseMessage(s string) (Message, error) {
+	fields := strings.Fields(strings.ToUpper(s))
+	if len(fields) < 2 || len(fields[0]) != 3 || strings.Trim(fields[0], "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return Message{}, fmt.Errorf("invalid message '%s': should have a Grundstellung and an indicator", s)
+	}
+
+	indicator, err := cyclometer.ParseIndicator(strings.Join(fields[1:], ""))
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{fields[0], indicator}, nil
+}
+
+// Solution is a rotor order and ring setting where every female of the messages matches a hole.
+type Solution struct {
+	Reflector string
+	Slow      string
+	Middle    string
+	Fast      string
+	Ring      string
+}
+
+// female is the position of the rotors (as numbers from 0 to 25) before the female letters 1 and 4 are encoded
+type female [3]int
+
+// Females returns the number of females in the messages. Each one can show up to 3 females (in the letters 1 and 4,
+// 2 and 5 or 3 and 6).
+func Females(messages []Message) int {
+	return len(females(messages))
+}
+
+func females(messages []Message) []female {
+	result := []female{}
+
+	for _, m := range messages {
+		g := m.Grundstellung
+		for p := 0; p < 3; p++ {
+			// a female in letters 2 and 5 is a female in letters 1 and 4 of a position one step ahead
+			if m.Indicator.Female(p) {
+				result = append(result, female{int(g[0] - 'A'), int(g[1] - 'A'), (int(g[2]-'A') + p) % 26})
+			}
+		}
+	}
+
+	return result
+}
+
+// Solve stacks the sheets of each rotor order over the females of the messages, and returns every rotor order and
+// ring setting where the light passes through all the sheets. More females mean fewer false solutions; the Polish
+// usually needed about 10 or 12 of them.
+func Solve(reflector string, orders [][3]string, messages []Message) ([]Solution, error) {
+	fs := females(messages)
+	if len(fs) == 0 {
+		return nil, errors.New("the messages should have at least one female")
+	}
+
+	solutions := []Solution{}
+
+	for _, order := range orders {
+		holes, err := allHoles(reflector, order)
+		if err != nil {
+			return nil, err
+		}
+
+		// the ring setting moves the sheet of every female by the same amount
+		for rs := 0; rs < 26; rs++ {
+			for rm := 0; rm < 26; rm++ {
+				for rf := 0; rf < 26; rf++ {
+					ok := true
+
+					for _, f := range fs {
+						if !holes[(f[0]-rs+26)%26][(f[1]-rm+26)%26][(f[2]-rf+26)%26] {
+							ok = false
+							break
+						}
+					}
+
+					if ok {
+						ring := string([]byte{byte('A' + rs), byte('A' + rm), byte('A' + rf)})
+						solutions = append(solutions, Solution{reflector, order[0], order[1], order[2], ring})
+					}
+				}
+			}
+		}
+	}
+
+	return solutions, nil
+}
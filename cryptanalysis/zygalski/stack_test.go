@@ -0,0 +1,66 @@
+package zygalski_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/cyclometer"
+	"github.com/ibraimgm/enigma/cryptanalysis/zygalski"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+// messages encodes random doubled keys with random Grundstellungen, until there are enough females. Messages where
+// the middle rotor turns over are skipped, since the sheets do not model it.
+func messages(t *testing.T, daily enigma.Settings, females int) []zygalski.Message {
+	e, err := enigma.WithSettings(daily)
+	assert.NoError(t, err)
+
+	rng := rand.New(rand.NewSource(1939))
+	letters := func() string {
+		return string([]byte{byte('A' + rng.Intn(26)), byte('A' + rng.Intn(26)), byte('A' + rng.Intn(26))})
+	}
+
+	result := []zygalski.Message{}
+	for zygalski.Females(result) < females {
+		ground, key := letters(), letters()
+		e.SetWindow(ground)
+
+		indicator, err := cyclometer.ParseIndicator(e.EncodeMessage(key+key, 0))
+		assert.NoError(t, err)
+
+		if e.Window()[:2] == ground[:2] {
+			result = append(result, zygalski.Message{Grundstellung: ground, Indicator: indicator})
+		}
+	}
+
+	return result
+}
+
+func TestParseMessage(t *testing.T) {
+	m, err := zygalski.ParseMessage("rtj wah wik")
+	assert.NoError(t, err)
+	assert.Equal(t, "RTJ", m.Grundstellung)
+	assert.Equal(t, "WAHWIK", m.Indicator.String())
+	assert.Equal(t, 1, zygalski.Females([]zygalski.Message{m}))
+
+	for _, s := range []string{"RTJ", "RT WAHWIK", "R1J WAHWIK"} {
+		_, err = zygalski.ParseMessage(s)
+		assert.EqualError(t, err, "invalid message '"+s+"': should have a Grundstellung and an indicator")
+	}
+
+	_, err = zygalski.ParseMessage("RTJ WAHWI")
+	assert.Error(t, err)
+}
+
+func TestSolve(t *testing.T) {
+	daily := enigma.Settings{Reflector: "B", Slow: "II", Middle: "I", Fast: "III", Ring: "FKR", Plugs: "AQBNCYEOFZGT"}
+	orders := [][3]string{{"I", "II", "III"}, {"II", "I", "III"}}
+
+	solutions, err := zygalski.Solve("B", orders, messages(t, daily, 12))
+	assert.NoError(t, err)
+	assert.Equal(t, []zygalski.Solution{{Reflector: "B", Slow: "II", Middle: "I", Fast: "III", Ring: "FKR"}}, solutions)
+
+	_, err = zygalski.Solve("B", orders, []zygalski.Message{})
+	assert.EqualError(t, err, "the messages should have at least one female")
+}
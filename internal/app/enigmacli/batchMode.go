@@ -0,0 +1,158 @@
+package enigmacli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	getopt "github.com/pborman/getopt/v2"
+)
+
+type batchInfo struct {
+	isHelp    bool
+	fileName  string
+	workers   int
+	blockSize uint
+	options   enigma.EncodeOptions
+}
+
+// batchJob is a line of the input of the 'batch' subcommand
+type batchJob struct {
+	Settings string `json:"settings"`
+	Text     string `json:"text"`
+}
+
+// batchResult is a line of the output of the 'batch' subcommand
+type batchResult struct {
+	Line  int    `json:"line"`
+	Text  string `json:"text"`
+	Error string `json:"error,omitempty"`
+}
+
+// parseBatchArgs parse the command line arguments of the 'batch' subcommand
+func parseBatchArgs(args []string, stdout io.Writer) (*batchInfo, error) {
+	getopt.CommandLine = getopt.New()
+	helpFlag := getopt.BoolLong("help", 'h', "Show usage and exit")
+	workersOpt := getopt.IntLong("workers", 'j', 0, "Number of messages coded at the same time (default: number of CPUs).", "4")
+	blockOpt := getopt.IntLong("blocksize", 'b', 5, "Block size of the coded text (default: 5)")
+	passthroughOpt := getopt.BoolLong("passthrough", 'p', "Keep non-letters and the letter case in the coded text.")
+
+	if err := parseGetopt(args); err != nil {
+		return nil, err
+	}
+
+	if *helpFlag {
+		getopt.SetParameters("[FILE]")
+		getopt.PrintUsage(stdout)
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Reads one message per line from FILE (or stdin, if no file is given), in the JSON format")
+		fmt.Fprintln(stdout, "  {\"settings\": \"B III II I AAA AAA AB CD\", \"text\": \"...\"}")
+		fmt.Fprintln(stdout, "with the settings in any notation accepted by the '-s' flag, and writes the coded messages in the same order,")
+		fmt.Fprintln(stdout, "one per line, as {\"line\": 1, \"text\": \"...\"} or {\"line\": 1, \"text\": \"\", \"error\": \"...\"}. Empty lines are skipped.")
+		return &batchInfo{isHelp: true}, nil
+	}
+
+	if *workersOpt < 0 {
+		return nil, errors.New("workers must be equal or greater than zero")
+	}
+
+	if *blockOpt < 0 {
+		return nil, errors.New("blocksize must be equal or greater than zero")
+	}
+
+	if len(getopt.Args()) > 1 {
+		return nil, errors.New("you should specify at most one input file")
+	}
+
+	info := &batchInfo{
+		workers:   *workersOpt,
+		blockSize: uint(*blockOpt),
+		options:   enigma.EncodeOptions{BlockSize: uint(*blockOpt), Passthrough: *passthroughOpt, PreserveCase: *passthroughOpt},
+	}
+
+	if len(getopt.Args()) == 1 {
+		info.fileName = getopt.Args()[0]
+	}
+
+	return info, nil
+}
+
+func runBatchMode(args []string, stdin io.Reader, stdout io.Writer) error {
+	info, err := parseBatchArgs(args, stdout)
+	if err != nil || info.isHelp {
+		return err
+	}
+
+	if info.fileName != "" {
+		f, err := os.Open(info.fileName)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		stdin = f
+	}
+
+	lines, jobs, errs, err := readBatch(stdin)
+	if err != nil {
+		return err
+	}
+
+	results := enigma.EncodeBatch(jobs, enigma.BatchOptions{Workers: info.workers, Encode: info.options})
+
+	w := bufio.NewWriter(stdout)
+	encoder := json.NewEncoder(w)
+
+	for i, r := range results {
+		result := batchResult{Line: lines[i], Text: r.Text}
+
+		if errs[i] != nil {
+			result.Error = errs[i].Error()
+		} else if r.Err != nil {
+			result.Error = r.Err.Error()
+		}
+
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+// readBatch reads the jobs of the input, with their line numbers. The lines that cannot be read are kept as jobs
+// without text, and their errors are returned in errs.
+func readBatch(r io.Reader) (lines []int, jobs []enigma.Job, errs []error, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var job batchJob
+		var settings enigma.Settings
+
+		err := json.Unmarshal([]byte(line), &job)
+		if err == nil {
+			settings, err = enigma.ParseSettingsString(job.Settings, enigma.AutoNotation)
+		}
+
+		if err != nil {
+			job.Text = ""
+		}
+
+		lines = append(lines, n)
+		jobs = append(jobs, enigma.Job{Settings: settings, Text: job.Text})
+		errs = append(errs, err)
+	}
+
+	return lines, jobs, errs, scanner.Err()
+}
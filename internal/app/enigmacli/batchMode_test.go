@@ -0,0 +1,87 @@
+package enigmacli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBatchArgs(t *testing.T) {
+	info, err := parseBatchArgs([]string{"batch"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &batchInfo{blockSize: 5, options: enigma.EncodeOptions{BlockSize: 5}}, info)
+
+	info, err = parseBatchArgs([]string{"batch", "-j", "4", "-b", "0", "-p", "jobs.jsonl"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "jobs.jsonl", info.fileName)
+	assert.Equal(t, 4, info.workers)
+	assert.Equal(t, enigma.EncodeOptions{Passthrough: true, PreserveCase: true}, info.options)
+
+	stdout := &strings.Builder{}
+	info, err = parseBatchArgs([]string{"batch", "-h"}, stdout)
+	assert.NoError(t, err)
+	assert.True(t, info.isHelp)
+	assert.Contains(t, stdout.String(), "Reads one message per line")
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"batch", "-j", "-1"}, "workers must be equal or greater than zero"},
+		{[]string{"batch", "-b", "-1"}, "blocksize must be equal or greater than zero"},
+		{[]string{"batch", "a.jsonl", "b.jsonl"}, "you should specify at most one input file"},
+	}
+
+	for _, test := range tests {
+		_, err := parseBatchArgs(test.args, nil)
+		assert.EqualError(t, err, test.expected, "%v", test.args)
+	}
+}
+
+func TestBatchModeOK(t *testing.T) {
+	stdin := strings.NewReader(`{"settings": "B III II I AAA AAA", "text": "enigma"}
+
+{"settings": "reflector=B rotors=III,II,I ring=AAA window=AAA", "text": "enigma"}
+{"settings": "B III II X AAA AAA", "text": "enigma"}
+{"settings": "B III II I AAA A", "text": "enigma"}
+not json
+{"settings": "B III II I AAA AAA", "text": "123"}
+`)
+	stdout := &strings.Builder{}
+
+	err := runBatchMode([]string{"batch", "-j", "2"}, stdin, stdout)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	assert.Len(t, lines, 6)
+	assert.Equal(t, `{"line":1,"text":"VWJBF I"}`, lines[0])
+	assert.Equal(t, `{"line":3,"text":"VWJBF I"}`, lines[1])
+	assert.Equal(t, `{"line":4,"text":"","error":"unrecognized rotor ID: 'X'"}`, lines[2])
+	assert.Contains(t, lines[3], `{"line":5,"text":"","error":"window settings`)
+	assert.Contains(t, lines[4], `{"line":6,"text":"","error":"invalid character`)
+
+	// an empty text is a result like any other
+	assert.Equal(t, `{"line":7,"text":""}`, lines[5])
+}
+
+func TestBatchModeFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "jobs.jsonl")
+	assert.NoError(t, os.WriteFile(name, []byte(`{"settings": "B III II I AAA AAA", "text": "Enigma!"}`+"\n"), 0644))
+
+	stdout := &strings.Builder{}
+	err := runBatchMode([]string{"batch", "-p", name}, strings.NewReader(""), stdout)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"line":1,"text":"Vwjbfi!"}`+"\n", stdout.String())
+
+	err = runBatchMode([]string{"batch", filepath.Join(t.TempDir(), "none.jsonl")}, strings.NewReader(""), stdout)
+	assert.Error(t, err)
+}
+
+func TestBatchModeError(t *testing.T) {
+	err := runBatchMode([]string{"batch"}, &mockReader{}, &strings.Builder{})
+	assert.EqualError(t, err, "some I/O error happened")
+}
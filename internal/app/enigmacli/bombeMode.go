@@ -0,0 +1,160 @@
+package enigmacli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/bombe"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/ibraimgm/enigma/text"
+	getopt "github.com/pborman/getopt/v2"
+)
+
+type bombeInfo struct {
+	isHelp  bool
+	isQuiet bool
+	crib    string
+	offset  int
+	isBest  bool
+	isMenu  bool
+	config  bombe.Config
+}
+
+// parseBombeArgs parse the command line arguments of the 'bombe' subcommand
+func parseBombeArgs(args []string, stdout io.Writer) (*bombeInfo, error) {
+	getopt.CommandLine = getopt.New()
+	helpFlag := getopt.BoolLong("help", 'h', "Show usage and exit")
+	cribOpt := getopt.StringLong("crib", 'c', "", "Known plaintext of the message.", "WETTER")
+	offsetOpt := getopt.IntLong("offset", 'n', 0, "Position of the crib in the ciphertext, starting with zero.", "0")
+	bestOpt := getopt.BoolLong("best", 0, "Use the crib offset with the best menu, instead of '-n'.")
+	menuOpt := getopt.BoolLong("menu", 0, "Print the menu analysis and the menu graph (in DOT format), without running the bombe.")
+	modelOpt := getopt.StringLong("model", 'm', "I", "Enigma model, whose rotors are tested in every order (I or M3).", "I")
+	rotorsOpt := getopt.StringLong("rotors", 'r', "", "Comma-separated list of rotors, to test a single rotor order.", "III,II,I")
+	reflectorOpt := getopt.StringLong("reflector", 'f', "B", "Reflector to use.", "B")
+	ringOpt := getopt.StringLong("ring", 'g', "AAA", "Ring settings assumed by the bombe.", "ABC")
+	quietOpt := getopt.BoolLong("quiet", 'q', "Do not print standard banner.")
+
+	if err := parseGetopt(args); err != nil {
+		return nil, err
+	}
+
+	if *helpFlag {
+		getopt.PrintUsage(stdout)
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "The bombe reads the ciphertext from stdin until EOF is reached, and tests every rotor order and position")
+		fmt.Fprintln(stdout, "against the crib, printing the stops found (with the implied plugs) in the '-s' compact notation.")
+		return &bombeInfo{isHelp: true}, nil
+	}
+
+	if *cribOpt == "" {
+		return nil, errors.New("you should specify the crib")
+	}
+
+	info := &bombeInfo{
+		isQuiet: *quietOpt,
+		crib:    *cribOpt,
+		offset:  *offsetOpt,
+		isBest:  *bestOpt,
+		isMenu:  *menuOpt,
+		config:  bombe.Config{Reflector: *reflectorOpt, Ring: *ringOpt},
+	}
+
+	if *rotorsOpt != "" {
+		rotors := strings.Split(*rotorsOpt, ",")
+		if len(rotors) != 3 {
+			return nil, errors.New("you should specify 3 rotor ID's")
+		}
+
+		info.config.Orders = [][3]string{{rotors[0], rotors[1], rotors[2]}}
+	} else {
+		model, ok := enigma.Models[*modelOpt]
+		if !ok {
+			return nil, errors.New("invalid model '" + *modelOpt + "'")
+		}
+
+		info.config.Orders = model.Orders()
+	}
+
+	return info, nil
+}
+
+func runBombeMode(args []string, stdin io.Reader, stdout io.Writer) error {
+	info, err := parseBombeArgs(args, stdout)
+	if err != nil || info.isHelp {
+		return err
+	}
+
+	input, err := io.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+
+	if info.isBest {
+		ranked := bombe.RankMenus(string(input), info.crib)
+		if len(ranked) == 0 {
+			return errors.New("the crib does not fit in the ciphertext at any offset")
+		}
+
+		info.offset = ranked[0].Offset
+	}
+
+	menu, err := bombe.BuildMenu(string(input), info.crib, info.offset)
+	if err != nil {
+		return err
+	}
+
+	if info.isMenu {
+		printMenu(menu, info.offset, stdout)
+		return nil
+	}
+
+	if !info.isQuiet {
+		fmt.Fprintf(stdout, "=>      Crib: \t%s\n", text.Letters(info.crib))
+		fmt.Fprintf(stdout, "=>    Offset: \t%d\n", info.offset)
+		fmt.Fprintf(stdout, "=>    Orders: \t%d\n", len(info.config.Orders))
+		fmt.Fprintln(stdout, "--- Running in 'bombe' mode ---")
+	}
+
+	stops, err := bombe.Run(menu, info.config)
+	if err != nil {
+		return err
+	}
+
+	for _, stop := range stops {
+		fmt.Fprintln(stdout, enigma.Settings{
+			Reflector: stop.Reflector,
+			Slow:      stop.Slow,
+			Middle:    stop.Middle,
+			Fast:      stop.Fast,
+			Ring:      info.config.Ring,
+			Window:    stop.Window,
+			Plugs:     stop.Steckers,
+		})
+	}
+
+	if !info.isQuiet {
+		fmt.Fprintf(stdout, "--- %d stop(s) found ---\n", len(stops))
+	}
+
+	return nil
+}
+
+func printMenu(menu *bombe.Menu, offset int, stdout io.Writer) {
+	score := menu.Score()
+
+	fmt.Fprintf(stdout, "=>     Offset: \t%d\n", offset)
+	fmt.Fprintf(stdout, "=>      Edges: \t%d\n", score.Edges)
+	fmt.Fprintf(stdout, "=>    Letters: \t%d\n", score.Letters)
+	fmt.Fprintf(stdout, "=> Components: \t%d (largest: %d letters)\n", score.Components, score.Largest)
+	fmt.Fprintf(stdout, "=>    Central: \t%c\n", menu.Central())
+	fmt.Fprintf(stdout, "=>      Loops: \t%d\n", score.Loops)
+
+	for _, loop := range menu.Loops() {
+		fmt.Fprintf(stdout, "      %s\n", loop.Letters)
+	}
+
+	fmt.Fprintln(stdout)
+	fmt.Fprint(stdout, menu.DOT())
+}
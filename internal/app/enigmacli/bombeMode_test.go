@@ -0,0 +1,80 @@
+package enigmacli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBombeArgsError(t *testing.T) {
+	tests := []struct {
+		args    []string
+		message string
+	}{
+		{[]string{"bombe"}, "you should specify the crib"},
+		{[]string{"bombe", "-c", "WETTER", "-m", "X"}, "invalid model 'X'"},
+		{[]string{"bombe", "-c", "WETTER", "-r", "I,II"}, "you should specify 3 rotor ID's"},
+		{[]string{"bombe", "-n", "X"}, "not a valid number: X"},
+	}
+
+	for _, test := range tests {
+		_, err := parseBombeArgs(test.args, nil)
+		assert.EqualError(t, err, test.message)
+	}
+}
+
+func TestParseBombeArgsOK(t *testing.T) {
+	info, err := parseBombeArgs([]string{"bombe", "-c", "WETTER", "-n", "3"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "WETTER", info.crib)
+	assert.Equal(t, 3, info.offset)
+	assert.Len(t, info.config.Orders, 60)
+
+	info, err = parseBombeArgs([]string{"bombe", "-c", "WETTER", "-r", "II,IV,I", "-m", "M3"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, [][3]string{{"II", "IV", "I"}}, info.config.Orders)
+
+	stdout := &strings.Builder{}
+	info, err = parseBombeArgs([]string{"bombe", "-h"}, stdout)
+	assert.NoError(t, err)
+	assert.True(t, info.isHelp)
+	assert.Contains(t, stdout.String(), "The bombe reads the ciphertext from stdin")
+}
+
+func TestBombeModeOK(t *testing.T) {
+	e, _ := enigma.WithSettings(enigma.Settings{Reflector: "B", Slow: "II", Middle: "IV", Fast: "I", Window: "QWE", Plugs: "ATBLDFGJHMNWOPQYRZVX"})
+	stdin := strings.NewReader(e.EncodeMessage("WETTERVORHERSAGEBISKAYAXWINDSTAERKEFUENF", 5))
+	stdout := &strings.Builder{}
+
+	err := runBombeMode([]string{"bombe", "-c", "WETTERVORHERSAGEBISKAYA", "-r", "II,IV,I"}, stdin, stdout)
+	assert.NoError(t, err)
+
+	output := stdout.String()
+	assert.Contains(t, output, "--- Running in 'bombe' mode ---")
+	assert.Contains(t, output, "B II IV I AAA QWE ")
+	assert.Contains(t, output, "--- 1 stop(s) found ---")
+}
+
+func TestBombeModeMenu(t *testing.T) {
+	stdout := &strings.Builder{}
+	err := runBombeMode([]string{"bombe", "-c", "ABC", "--best", "--menu"}, strings.NewReader("BCDXXBCA"), stdout)
+	assert.NoError(t, err)
+
+	output := stdout.String()
+	assert.Contains(t, output, "=>     Offset: \t5\n")
+	assert.Contains(t, output, "=>      Loops: \t1\n      BAC\n")
+	assert.Contains(t, output, "graph menu {\n  A -- B [label=\"6\"];\n")
+}
+
+func TestBombeModeError(t *testing.T) {
+	err := runBombeMode([]string{"bombe", "-c", "WETTER"}, strings.NewReader("ABC"), &strings.Builder{})
+	assert.EqualError(t, err, "the crib does not fit in the ciphertext at offset 0")
+
+	err = runBombeMode([]string{"bombe", "-c", "ABC", "--best"}, strings.NewReader("ABC"), &strings.Builder{})
+	assert.EqualError(t, err, "the crib does not fit in the ciphertext at any offset")
+
+	err = runBombeMode([]string{"bombe", "-c", "WETTER"}, &mockReader{}, &strings.Builder{})
+	assert.EqualError(t, err, "some I/O error happened")
+}
@@ -0,0 +1,129 @@
+package enigmacli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/crack"
+	"github.com/ibraimgm/enigma/cryptanalysis/scoring"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/ibraimgm/enigma/text"
+	getopt "github.com/pborman/getopt/v2"
+)
+
+type crackInfo struct {
+	isHelp  bool
+	isQuiet bool
+	options crack.Options
+}
+
+// parseCrackArgs parse the command line arguments of the 'crack' subcommand
+func parseCrackArgs(args []string, stdout io.Writer) (*crackInfo, error) {
+	getopt.CommandLine = getopt.New()
+	helpFlag := getopt.BoolLong("help", 'h', "Show usage and exit")
+	modelOpt := getopt.StringLong("model", 'm', "I", "Enigma model, whose rotors are tested in every order (I or M3).", "I")
+	rotorsOpt := getopt.StringLong("rotors", 'r', "", "Comma-separated list of rotors, to test a single rotor order.", "III,II,I")
+	reflectorOpt := getopt.StringLong("reflector", 'f', "B", "Comma-separated list of reflectors to test.", "B,C")
+	plugsOpt := getopt.IntLong("plugs", 'p', 10, "Maximum number of plug pairs.", "10")
+	candidatesOpt := getopt.IntLong("candidates", 'k', 10, "Number of start positions kept for the ring and plugboard search.", "10")
+	languageOpt := getopt.StringLong("language", 'l', "german", "Language of the plaintext (german or english).", "german")
+	ngramOpt := getopt.IntLong("ngram", 'g', 2, "Size of the n-grams used to score the plaintext, from 2 to 4.", "3")
+	ngramFileOpt := getopt.StringLong("ngram-file", 0, "", "File with custom n-gram counts (one 'NGRAM COUNT' per line), instead of '-l' and '-g'.", "quadgrams.txt")
+	workersOpt := getopt.IntLong("workers", 'w', 0, "Number of parallel workers (default: one per CPU).", "4")
+	quietOpt := getopt.BoolLong("quiet", 'q', "Do not print standard banner and progress.")
+
+	if err := parseGetopt(args); err != nil {
+		return nil, err
+	}
+
+	if *helpFlag {
+		getopt.PrintUsage(stdout)
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Reads the ciphertext from stdin until EOF is reached, and searches the key without any known plaintext,")
+		fmt.Fprintln(stdout, "printing the best key found (in the '-s' compact notation) and the decoded text. Long messages (about")
+		fmt.Fprintln(stdout, "500 letters or more) are needed for the attack to succeed. Press Ctrl+C to cancel the search.")
+		return &crackInfo{isHelp: true}, nil
+	}
+
+	info := &crackInfo{
+		isQuiet: *quietOpt,
+		options: crack.Options{
+			Reflectors: strings.Split(*reflectorOpt, ","),
+			Candidates: *candidatesOpt,
+			MaxPlugs:   *plugsOpt,
+			Workers:    *workersOpt,
+		},
+	}
+
+	var err error
+	if *ngramFileOpt != "" {
+		info.options.Scorer, err = scoring.LoadNGramsFile(*ngramFileOpt)
+	} else {
+		info.options.Scorer, err = scoring.Builtin(scoring.Language(*languageOpt), *ngramOpt)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if *rotorsOpt != "" {
+		rotors := strings.Split(*rotorsOpt, ",")
+		if len(rotors) != 3 {
+			return nil, errors.New("you should specify 3 rotor ID's")
+		}
+
+		info.options.Orders = [][3]string{{rotors[0], rotors[1], rotors[2]}}
+	} else {
+		model, ok := enigma.Models[*modelOpt]
+		if !ok {
+			return nil, errors.New("invalid model '" + *modelOpt + "'")
+		}
+
+		info.options.Orders = model.Orders()
+	}
+
+	return info, nil
+}
+
+func runCrackMode(args []string, stdin io.Reader, stdout io.Writer) error {
+	info, err := parseCrackArgs(args, stdout)
+	if err != nil || info.isHelp {
+		return err
+	}
+
+	input, err := io.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+
+	if !info.isQuiet {
+		fmt.Fprintf(stdout, "=>   Letters: \t%d\n", len(text.Letters(string(input))))
+		fmt.Fprintf(stdout, "=>    Orders: \t%d\n", len(info.options.Orders)*len(info.options.Reflectors))
+		fmt.Fprintln(stdout, "--- Running in 'crack' mode ---")
+
+		info.options.Progress = func(done, total int) {
+			fmt.Fprintf(os.Stderr, "\r=> %d/%d rotor orders tested", done, total)
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := crack.Crack(ctx, string(input), info.options)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(stdout, result.Settings)
+	fmt.Fprintln(stdout, result.Plaintext)
+
+	return nil
+}
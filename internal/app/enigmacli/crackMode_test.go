@@ -0,0 +1,61 @@
+package enigmacli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCrackArgs(t *testing.T) {
+	info, err := parseCrackArgs([]string{"crack"}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, info.options.Orders, 60)
+	assert.Equal(t, []string{"B"}, info.options.Reflectors)
+	assert.Equal(t, 10, info.options.MaxPlugs)
+
+	info, err = parseCrackArgs([]string{"crack", "-r", "II,V,III", "-f", "B,C", "-p", "6", "-q"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, [][3]string{{"II", "V", "III"}}, info.options.Orders)
+	assert.Equal(t, []string{"B", "C"}, info.options.Reflectors)
+	assert.Equal(t, 6, info.options.MaxPlugs)
+	assert.True(t, info.isQuiet)
+
+	_, err = parseCrackArgs([]string{"crack", "-r", "I,II"}, nil)
+	assert.EqualError(t, err, "you should specify 3 rotor ID's")
+
+	_, err = parseCrackArgs([]string{"crack", "-l", "english", "-g", "5"}, nil)
+	assert.EqualError(t, err, "no statistics for language 'english' with n = 5")
+
+	info, err = parseCrackArgs([]string{"crack", "-l", "english", "-g", "4"}, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, info.options.Scorer)
+
+	_, err = parseCrackArgs([]string{"crack", "--ngram-file", "/nonexistent/file.txt"}, nil)
+	assert.Error(t, err)
+
+	_, err = parseCrackArgs([]string{"crack", "-m", "X"}, nil)
+	assert.EqualError(t, err, "invalid model 'X'")
+
+	stdout := &strings.Builder{}
+	info, err = parseCrackArgs([]string{"crack", "-h"}, stdout)
+	assert.NoError(t, err)
+	assert.True(t, info.isHelp)
+	assert.Contains(t, stdout.String(), "searches the key without any known plaintext")
+}
+
+func TestCrackModeOK(t *testing.T) {
+	plain := strings.Repeat("DIEDIVISIONMELDETSTARKEVERLUSTEUNDBITTETUMVERSTAERKUNGXDERANGRIFFSOLLAMFRUEHENMORGENBEGINNEN", 4)
+	e, _ := enigma.WithSettings(enigma.Settings{Reflector: "B", Slow: "I", Middle: "II", Fast: "III", Window: "KZX"})
+
+	stdout := &strings.Builder{}
+	err := runCrackMode([]string{"crack", "-r", "I,II,III", "-p", "1", "-q"}, strings.NewReader(e.EncodeMessage(plain, 0)), stdout)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(stdout.String(), plain+"\n"))
+}
+
+func TestCrackModeError(t *testing.T) {
+	err := runCrackMode([]string{"crack"}, &mockReader{}, &strings.Builder{})
+	assert.EqualError(t, err, "some I/O error happened")
+}
@@ -0,0 +1,71 @@
+package enigmacli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/crib"
+	getopt "github.com/pborman/getopt/v2"
+)
+
+type cribInfo struct {
+	isHelp  bool
+	isQuiet bool
+	crib    string
+}
+
+// parseCribArgs parse the command line arguments of the 'crib' subcommand
+func parseCribArgs(args []string, stdout io.Writer) (*cribInfo, error) {
+	getopt.CommandLine = getopt.New()
+	helpFlag := getopt.BoolLong("help", 'h', "Show usage and exit")
+	cribOpt := getopt.StringLong("crib", 'c', "", "Known plaintext of the message.", "WETTER")
+	quietOpt := getopt.BoolLong("quiet", 'q', "Print only the offsets, without the alignment.")
+
+	if err := parseGetopt(args); err != nil {
+		return nil, err
+	}
+
+	if *helpFlag {
+		getopt.PrintUsage(stdout)
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Reads the ciphertext from stdin until EOF is reached, and prints every offset where the crib can be placed")
+		fmt.Fprintln(stdout, "without a letter being encoded to itself, together with the alignment of the crib.")
+		return &cribInfo{isHelp: true}, nil
+	}
+
+	if *cribOpt == "" {
+		return nil, errors.New("you should specify the crib")
+	}
+
+	return &cribInfo{isQuiet: *quietOpt, crib: *cribOpt}, nil
+}
+
+func runCribMode(args []string, stdin io.Reader, stdout io.Writer) error {
+	info, err := parseCribArgs(args, stdout)
+	if err != nil || info.isHelp {
+		return err
+	}
+
+	input, err := io.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+
+	ciphertext := string(input)
+	positions := crib.Positions(ciphertext, info.crib)
+
+	for _, offset := range positions {
+		if info.isQuiet {
+			fmt.Fprintln(stdout, offset)
+		} else {
+			fmt.Fprintln(stdout, crib.Visualize(ciphertext, info.crib, offset))
+		}
+	}
+
+	if !info.isQuiet {
+		fmt.Fprintf(stdout, "--- %d offset(s) found ---\n", len(positions))
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package enigmacli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCribArgs(t *testing.T) {
+	_, err := parseCribArgs([]string{"crib"}, nil)
+	assert.EqualError(t, err, "you should specify the crib")
+
+	info, err := parseCribArgs([]string{"crib", "-c", "WETTER", "-q"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "WETTER", info.crib)
+	assert.True(t, info.isQuiet)
+
+	stdout := &strings.Builder{}
+	info, err = parseCribArgs([]string{"crib", "-h"}, stdout)
+	assert.NoError(t, err)
+	assert.True(t, info.isHelp)
+	assert.Contains(t, stdout.String(), "prints every offset where the crib can be placed")
+}
+
+func TestCribModeOK(t *testing.T) {
+	stdout := &strings.Builder{}
+	err := runCribMode([]string{"crib", "-c", "BC"}, strings.NewReader("ABCDE"), stdout)
+	assert.NoError(t, err)
+	assert.Equal(t, "    0  AB\n       BC\n\n    2  CD\n       BC\n\n    3  DE\n       BC\n\n--- 3 offset(s) found ---\n", stdout.String())
+
+	stdout = &strings.Builder{}
+	err = runCribMode([]string{"crib", "-c", "BC", "-q"}, strings.NewReader("ABCDE"), stdout)
+	assert.NoError(t, err)
+	assert.Equal(t, "0\n2\n3\n", stdout.String())
+}
+
+func TestCribModeError(t *testing.T) {
+	err := runCribMode([]string{"crib", "-c", "BC"}, &mockReader{}, &strings.Builder{})
+	assert.EqualError(t, err, "some I/O error happened")
+}
@@ -0,0 +1,130 @@
+package enigmacli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/cyclometer"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	getopt "github.com/pborman/getopt/v2"
+)
+
+type cyclometerInfo struct {
+	isHelp    bool
+	isQuiet   bool
+	reflector string
+	orders    [][3]string
+	catalog   string
+}
+
+// parseCyclometerArgs parse the command line arguments of the 'cyclometer' subcommand
+func parseCyclometerArgs(args []string, stdout io.Writer) (*cyclometerInfo, error) {
+	getopt.CommandLine = getopt.New()
+	helpFlag := getopt.BoolLong("help", 'h', "Show usage and exit")
+	modelOpt := getopt.StringLong("model", 'm', "I", "Enigma model, whose rotors are tested in every order (I or M3).", "I")
+	rotorsOpt := getopt.StringLong("rotors", 'r', "", "Comma-separated list of rotors, to test a single rotor order.", "III,II,I")
+	reflectorOpt := getopt.StringLong("reflector", 'f', "B", "Reflector to use.", "B")
+	catalogOpt := getopt.StringLong("catalog", 0, "", "File where the catalog is cached (default: in the user cache directory).", "catalog.gob")
+	quietOpt := getopt.BoolLong("quiet", 'q', "Do not print standard banner.")
+
+	if err := parseGetopt(args); err != nil {
+		return nil, err
+	}
+
+	if *helpFlag {
+		getopt.PrintUsage(stdout)
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Reads the doubled indicators of a day (6 letters each) from stdin until EOF is reached, computes the")
+		fmt.Fprintln(stdout, "characteristic and prints every rotor order and Grundstellung (with rings 'AAA') that has it. The catalog")
+		fmt.Fprintln(stdout, "of characteristics is built in the first run, and cached on disk for the next ones.")
+		return &cyclometerInfo{isHelp: true}, nil
+	}
+
+	info := &cyclometerInfo{isQuiet: *quietOpt, reflector: *reflectorOpt, catalog: *catalogOpt}
+	name := *modelOpt
+
+	if *rotorsOpt != "" {
+		rotors := strings.Split(*rotorsOpt, ",")
+		if len(rotors) != 3 {
+			return nil, errors.New("you should specify 3 rotor ID's")
+		}
+
+		info.orders = [][3]string{{rotors[0], rotors[1], rotors[2]}}
+		name = strings.Join(rotors, "_")
+	} else {
+		model, ok := enigma.Models[*modelOpt]
+		if !ok {
+			return nil, errors.New("invalid model '" + *modelOpt + "'")
+		}
+
+		info.orders = model.Orders()
+	}
+
+	if info.catalog == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+
+		file := strings.ReplaceAll(fmt.Sprintf("cyclometer-%s-%s.gob", info.reflector, name), " ", "_")
+		info.catalog = filepath.Join(dir, "enigma", file)
+	}
+
+	return info, nil
+}
+
+func runCyclometerMode(args []string, stdin io.Reader, stdout io.Writer) error {
+	info, err := parseCyclometerArgs(args, stdout)
+	if err != nil || info.isHelp {
+		return err
+	}
+
+	input, err := io.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+
+	indicators, err := cyclometer.ParseIndicators(string(input))
+	if err != nil {
+		return err
+	}
+
+	characteristic, err := cyclometer.FromIndicators(indicators)
+	if err != nil {
+		return err
+	}
+
+	if !info.isQuiet {
+		fmt.Fprintf(stdout, "=>     Indicators: \t%d\n", len(indicators))
+		fmt.Fprintf(stdout, "=> Characteristic: \t%s\n", characteristic)
+		fmt.Fprintf(stdout, "=>        Catalog: \t%s\n", info.catalog)
+		fmt.Fprintln(stdout, "--- Running in 'cyclometer' mode ---")
+	}
+
+	catalog, err := cyclometer.LoadCatalog(info.catalog, info.reflector, info.orders)
+	if err != nil {
+		return err
+	}
+
+	entries := catalog.Lookup(characteristic)
+	for _, e := range entries {
+		fmt.Fprintln(stdout, enigma.Settings{
+			Reflector: e.Reflector,
+			Slow:      e.Slow,
+			Middle:    e.Middle,
+			Fast:      e.Fast,
+			Ring:      "AAA",
+			Window:    e.Window,
+		})
+	}
+
+	if !info.isQuiet {
+		fmt.Fprintf(stdout, "--- %d position(s) found ---\n", len(entries))
+	}
+
+	return nil
+}
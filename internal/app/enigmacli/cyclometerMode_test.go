@@ -0,0 +1,67 @@
+package enigmacli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCyclometerArgs(t *testing.T) {
+	info, err := parseCyclometerArgs([]string{"cyclometer"}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, info.orders, 60)
+	assert.Equal(t, "cyclometer-B-I.gob", filepath.Base(info.catalog))
+
+	info, err = parseCyclometerArgs([]string{"cyclometer", "-r", "II,I,III", "-f", "C", "--catalog", "x.gob", "-q"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, [][3]string{{"II", "I", "III"}}, info.orders)
+	assert.Equal(t, "C", info.reflector)
+	assert.Equal(t, "x.gob", info.catalog)
+	assert.True(t, info.isQuiet)
+
+	_, err = parseCyclometerArgs([]string{"cyclometer", "-r", "I,II"}, nil)
+	assert.EqualError(t, err, "you should specify 3 rotor ID's")
+
+	_, err = parseCyclometerArgs([]string{"cyclometer", "-m", "X"}, nil)
+	assert.EqualError(t, err, "invalid model 'X'")
+
+	stdout := &strings.Builder{}
+	info, err = parseCyclometerArgs([]string{"cyclometer", "-h"}, stdout)
+	assert.NoError(t, err)
+	assert.True(t, info.isHelp)
+	assert.Contains(t, stdout.String(), "computes the")
+}
+
+func TestCyclometerModeOK(t *testing.T) {
+	e, _ := enigma.WithSettings(enigma.Settings{Reflector: "B", Slow: "II", Middle: "I", Fast: "III", Window: "KDR", Plugs: "AQBN"})
+	indicators := []string{}
+
+	for i := 0; i < 26*26; i += 7 {
+		key := string([]byte{byte('A' + i%26), byte('A' + i/26), byte('A' + (i*3)%26)})
+		e.SetWindow("KDR")
+		indicators = append(indicators, e.EncodeMessage(key+key, 0))
+	}
+
+	catalog := filepath.Join(t.TempDir(), "catalog.gob")
+	stdout := &strings.Builder{}
+	err := runCyclometerMode([]string{"cyclometer", "-r", "II,I,III", "--catalog", catalog}, strings.NewReader(strings.Join(indicators, "\n")), stdout)
+	assert.NoError(t, err)
+	assert.Contains(t, stdout.String(), "B II I III AAA KDR\n")
+	assert.Contains(t, stdout.String(), "--- Running in 'cyclometer' mode ---")
+}
+
+func TestCyclometerModeError(t *testing.T) {
+	catalog := filepath.Join(t.TempDir(), "catalog.gob")
+
+	err := runCyclometerMode([]string{"cyclometer", "--catalog", catalog}, &mockReader{}, &strings.Builder{})
+	assert.EqualError(t, err, "some I/O error happened")
+
+	err = runCyclometerMode([]string{"cyclometer", "--catalog", catalog}, strings.NewReader("ABC"), &strings.Builder{})
+	assert.EqualError(t, err, "invalid indicator 'ABC': should have 6 letters")
+
+	err = runCyclometerMode([]string{"cyclometer", "--catalog", catalog}, strings.NewReader("ABCDEF"), &strings.Builder{})
+	assert.Error(t, err)
+}
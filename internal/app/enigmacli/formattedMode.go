@@ -0,0 +1,29 @@
+package enigmacli
+
+import (
+	"fmt"
+	"io"
+)
+
+func runFormattedMode(info *parseInfo, stdin io.Reader, stdout, file io.Writer) error {
+	if !info.isQuiet {
+		printBanner(info, stdout)
+		fmt.Fprintln(stdout, "--- Running in 'formatted' mode; EOF to finish the message ---")
+	}
+
+	input, err := io.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+
+	message := string(input)
+	if info.convention != nil {
+		message = info.convention.Prepare(message)
+	}
+
+	// the padding is encoded with the message, so the letter count of the header is the count of encoded letters
+	message = info.formatter.Pad(message)
+
+	_, err = io.WriteString(file, info.formatter.Format(info.e.EncodeMessage(message, 0)))
+	return err
+}
@@ -0,0 +1,47 @@
+package enigmacli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/ibraimgm/enigma/text"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormattedModeOK(t *testing.T) {
+	stdin := strings.NewReader("enigma\nmachine\n")
+	stdout := &strings.Builder{}
+	info := &parseInfo{
+		e: enigma.WithDefaults(),
+		formatter: &text.Formatter{
+			GroupsPerLine: 2,
+			Padding:       text.PadWithX,
+			Header:        &text.Header{Time: "1230"},
+		},
+	}
+
+	err := runFormattedMode(info, stdin, stdout, stdout)
+	assert.NoError(t, err)
+
+	output := stdout.String()
+	assert.Contains(t, output, "--- Running in 'formatted' mode; EOF to finish the message ---")
+	assert.Contains(t, output, "1230 - 1tle - 1tl - 15 -\nVWJBF IGXKX\nEOSYD\n")
+
+	// the padding was encoded with the message
+	assert.Equal(t, "ENIGMAMACHINEXX", enigma.WithDefaults().EncodeMessage("VWJBFIGXKXEOSYD", 0))
+}
+
+func TestFormattedModeError(t *testing.T) {
+	stdin := &mockReader{}
+	stdout := &strings.Builder{}
+	info := &parseInfo{
+		e:         enigma.WithDefaults(),
+		isQuiet:   true,
+		formatter: &text.Formatter{},
+	}
+
+	err := runFormattedMode(info, stdin, stdout, stdout)
+	assert.EqualError(t, err, "some I/O error happened")
+	assert.Empty(t, stdout.String())
+}
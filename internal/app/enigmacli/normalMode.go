@@ -4,23 +4,59 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
 )
 
 func runNormalMode(info *parseInfo, stdin io.Reader, stdout, file io.Writer) error {
-	e := info.e
-
 	if !info.isQuiet {
-		fmt.Fprintf(stdout, "=>    Rotors: \t%s,%s,%s\n", e.Slow(), e.Middle(), e.Fast())
-		fmt.Fprintf(stdout, "=> Reflector: \t%s\n", e.Reflector())
-		fmt.Fprintf(stdout, "=>      Ring: \t%s\n", e.Ring())
-		fmt.Fprintf(stdout, "=>    Window: \t%s\n", e.Window())
+		printBanner(info, stdout)
 		fmt.Fprintln(stdout, "--- Running in 'normal' mode; EOF to exit ---")
 	}
 
 	scanner := bufio.NewScanner(stdin)
 	for scanner.Scan() {
-		fmt.Fprintln(file, e.EncodeMessage(scanner.Text(), info.blockSize))
+		fmt.Fprintln(file, encodeLine(info, scanner.Text()))
 	}
 
 	return scanner.Err()
 }
+
+func printBanner(info *parseInfo, stdout io.Writer) {
+	e := info.e
+
+	fmt.Fprintf(stdout, "=>    Rotors: \t%s,%s,%s\n", e.Slow(), e.Middle(), e.Fast())
+	fmt.Fprintf(stdout, "=> Reflector: \t%s\n", e.Reflector())
+	fmt.Fprintf(stdout, "=>      Ring: \t%s\n", e.Ring())
+	fmt.Fprintf(stdout, "=>    Window: \t%s\n", info.settings.Window)
+	if info.offset != 0 {
+		fmt.Fprintf(stdout, "=>    Offset: \t%d\n", info.offset)
+	}
+	if info.settings.Plugs != "" {
+		fmt.Fprintf(stdout, "=>     Plugs: \t%s\n", info.settings.Plugs)
+	}
+	if info.convention != nil {
+		fmt.Fprintf(stdout, "=>Convention: \t%s\n", info.convention.Name())
+	}
+}
+
+func encodeLine(info *parseInfo, line string) string {
+	e := info.e
+	options := enigma.EncodeOptions{
+		BlockSize:    info.blockSize,
+		Passthrough:  info.isPassthrough,
+		PreserveCase: info.isPassthrough,
+		Backspace:    info.isBackspace,
+	}
+
+	if info.convention == nil {
+		return e.EncodeMessageWith(line, options)
+	}
+
+	if info.isDecrypt {
+		options.BlockSize = 0
+		return info.convention.Restore(e.EncodeMessageWith(line, options))
+	}
+
+	return e.EncodeMessageWith(info.convention.Prepare(line), options)
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/ibraimgm/enigma/text"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,6 +26,45 @@ func TestNormalModeOK(t *testing.T) {
 	assert.Contains(t, output, "VWJBF I")
 }
 
+func TestNormalModeConvention(t *testing.T) {
+	stdin := strings.NewReader("Angriff um 5 Uhr.")
+	stdout := &strings.Builder{}
+	info := &parseInfo{
+		e:          enigma.WithDefaults(),
+		blockSize:  5,
+		convention: text.Conventions["heer"],
+	}
+
+	err := runNormalMode(info, stdin, stdout, stdout)
+	assert.NoError(t, err)
+
+	coded := strings.TrimSpace(stdout.String()[strings.LastIndex(stdout.String(), "---")+3:])
+	stdin = strings.NewReader(coded)
+	stdout = &strings.Builder{}
+	info.e = enigma.WithDefaults()
+	info.isDecrypt = true
+	info.isQuiet = true
+
+	err = runNormalMode(info, stdin, stdout, stdout)
+	assert.NoError(t, err)
+	assert.Equal(t, "ANGRIFFUMFUENFUHR.\n", stdout.String())
+}
+
+func TestNormalModePassthrough(t *testing.T) {
+	stdin := strings.NewReader("Enigma, 2 machines!")
+	stdout := &strings.Builder{}
+	info := &parseInfo{
+		e:             enigma.WithDefaults(),
+		blockSize:     5,
+		isQuiet:       true,
+		isPassthrough: true,
+	}
+
+	err := runNormalMode(info, stdin, stdout, stdout)
+	assert.NoError(t, err)
+	assert.Equal(t, "Vwjbfi, 2 gxkxeosz!\n", stdout.String())
+}
+
 type mockReader struct{}
 
 func (r *mockReader) Read(p []byte) (int, error) {
@@ -45,3 +85,48 @@ func TestNormalModeError(t *testing.T) {
 	output := stdout.String()
 	assert.Contains(t, output, "--- Running in 'normal' mode; EOF to exit ---")
 }
+
+func TestNormalModeOffset(t *testing.T) {
+	info, err := parseArgs([]string{"cmd", "--offset", "2"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), info.offset)
+
+	stdin := strings.NewReader("igma")
+	stdout := &strings.Builder{}
+
+	err = runNormalMode(info, stdin, stdout, stdout)
+	assert.NoError(t, err)
+
+	output := stdout.String()
+	assert.Contains(t, output, "=>    Window: \tAAA\n=>    Offset: \t2\n")
+	assert.Contains(t, output, "JBFI")
+}
+
+func TestNormalModeBackspace(t *testing.T) {
+	stdin := strings.NewReader("enigmx\bA\nmachine")
+	stdout := &strings.Builder{}
+	info := &parseInfo{
+		e:           enigma.WithDefaults(),
+		blockSize:   5,
+		isQuiet:     true,
+		isBackspace: true,
+	}
+
+	err := runNormalMode(info, stdin, stdout, stdout)
+	assert.NoError(t, err)
+	assert.Equal(t, "VWJBF I\nGXKXE OS\n", stdout.String())
+}
+
+func TestNormalModePassthroughKeepsBackspace(t *testing.T) {
+	stdin := strings.NewReader("Enigmx\b\x7f!")
+	stdout := &strings.Builder{}
+	info := &parseInfo{
+		e:             enigma.WithDefaults(),
+		isQuiet:       true,
+		isPassthrough: true,
+	}
+
+	err := runNormalMode(info, stdin, stdout, stdout)
+	assert.NoError(t, err)
+	assert.Equal(t, "Vwjbft\b\x7f!\n", stdout.String())
+}
@@ -4,20 +4,31 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ibraimgm/enigma/machine/enigma"
 	"github.com/ibraimgm/enigma/machine/parts"
+	"github.com/ibraimgm/enigma/text"
 	getopt "github.com/pborman/getopt/v2"
 )
 
 type parseInfo struct {
-	e         enigma.Enigma
-	fileName  string
-	isQuiet   bool
-	isHelp    bool
-	blockSize uint
+	e             enigma.Enigma
+	settings      enigma.Settings
+	fileName      string
+	isQuiet       bool
+	isHelp        bool
+	blockSize     uint
+	convention    text.Convention
+	isDecrypt     bool
+	isPassthrough bool
+	isBackspace   bool
+	formatter     *text.Formatter
+	isRandomKey   bool
+	offset        uint64
 }
 
 // parseArgs parse command line arguments and returns a new enigma instance and a boolean indicating
@@ -29,9 +40,25 @@ func parseArgs(args []string, stdout io.Writer) (*parseInfo, error) {
 	reflectorOpt := getopt.StringLong("reflector", 'f', "B", "Reflector to use.", "B")
 	ringOpt := getopt.StringLong("ring", 'g', "AAA", "Ring settings to be used.", "ABC")
 	windowOpt := getopt.StringLong("window", 'w', "AAA", "Window settings to be used.", "ABC")
+	settingsOpt := getopt.StringLong("settings", 's', "", "Full machine settings, replacing -r, -f, -g and -w (ex: \"B III II I AAA AAA AB CD\").", "SETTINGS")
+	randomOpt := getopt.BoolLong("random-key", 0, "Use a random key (printed to stderr), replacing -r, -f, -g, -w and -s.")
+	modelOpt := getopt.StringLong("model", 0, "M3", "Enigma model used for the random key (I or M3).", "M3")
+	offsetOpt := getopt.Uint64Long("offset", 0, 0, "Number of letters of the message to skip, to start coding from its middle.", "100")
+	seedOpt := getopt.Int64Long("seed", 0, 0, "Seed used for the random key, for reproducible keys (default: secure random).", "42")
 	blockOpt := getopt.IntLong("blocksize", 'b', 5, "Block size of the coded text (default: 5)")
 	fileOpt := getopt.StringLong("output", 'o', "", "Output file to write.", "a.txt")
 	quietOpt := getopt.BoolLong("quiet", 'q', "Do not print standard banner.")
+	conventionOpt := getopt.StringLong("convention", 'c', "", "Text convention to use (heer, heer-y or kriegsmarine).", "heer")
+	passthroughOpt := getopt.BoolLong("passthrough", 'p', "Keep non-letters and the letter case in the coded text.")
+	backspaceOpt := getopt.BoolLong("backspace", 0, "Make a backspace (or delete) character erase the last character typed.")
+	decryptOpt := getopt.BoolLong("decrypt", 'd', "Restore the coded text using the text convention, instead of preparing it.")
+	groupsOpt := getopt.IntLong("groups-per-line", 'l', 0, "Number of blocks in each line of the formatted text.", "10")
+	padOpt := getopt.StringLong("pad", 0, "", "Complete the last block of the formatted text with 'x' or 'nulls'.", "x")
+	numberOpt := getopt.BoolLong("number-lines", 'n', "Number the lines of the formatted text.")
+	headerOpt := getopt.BoolLong("header", 0, "Write a radiogram header before the formatted text.")
+	timeOpt := getopt.StringLong("time", 0, "", "Time written in the header (default: current time).", "1230")
+	partOpt := getopt.StringLong("part", 0, "1/1", "Part number and total parts written in the header.", "1/2")
+	indicatorOpt := getopt.StringLong("indicator", 0, "", "Indicator written in the header.", "WZE HPN")
 
 	if err := parseGetopt(args); err != nil {
 		return nil, err
@@ -43,7 +70,25 @@ func parseArgs(args []string, stdout io.Writer) (*parseInfo, error) {
 		fmt.Fprintln(stdout, "All command-line arguments are optional.")
 		fmt.Fprintln(stdout, "By default, enigma run in 'normal' mode, which reads one line from sdtin and outputs encoded text, until EOF is reached.")
 		fmt.Fprintln(stdout, "This means that after writing a line and pressing 'Enter', the coded version will be displayed immediately (written to file).")
+		fmt.Fprintln(stdout, "With '--backspace', a backspace (or delete) character in a line erases the last character typed, turning the rotors back.")
 		fmt.Fprintln(stdout, "The coding process will output the characters in 'blocks', whose size can be controlled with the '-b' flag.")
+		fmt.Fprintln(stdout, "The '-s' flag accepts the settings in the notations used by other emulators and key sheets:")
+		fmt.Fprintln(stdout, "  \"B III II I AAA AAA AB CD\", \"B III II I 01 01 01 AAA AB CD\" or \"reflector=B rotors=III,II,I ring=AAA window=AAA plugs=AB,CD\".")
+		fmt.Fprintln(stdout, "With '--random-key', a random key is generated and written to stderr, using the '-s' compact notation.")
+		fmt.Fprintln(stdout, "With '--offset', the rotors are moved as if that many letters were already coded, to decode a fragment of a message.")
+		fmt.Fprintln(stdout, "With the '-p' flag, characters that cannot be coded are kept in their positions, and blocks are not used.")
+		fmt.Fprintln(stdout, "When a text convention is specified with '-c', the input is prepared using the convention rules (numbers, umlauts, punctuation).")
+		fmt.Fprintln(stdout, "When '-d' is also specified, the output is restored using the same convention, instead.")
+		fmt.Fprintln(stdout, "The formatting flags ('-l', '-n', '--pad' and '--header') make enigma read the whole input until EOF, and write it")
+		fmt.Fprintln(stdout, "as a formatted message, like the historical signal forms.")
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Subcommands (use '--help' on each one for details):")
+		fmt.Fprintln(stdout, "  enigma batch        Code many messages, each with its own settings, read from a JSONL file.")
+		fmt.Fprintln(stdout, "  enigma bombe        Find the rotor order and positions from a crib, simulating the Turing-Welchman bombe.")
+		fmt.Fprintln(stdout, "  enigma crack        Find the key of a long message without a crib (ciphertext-only attack).")
+		fmt.Fprintln(stdout, "  enigma crib         Find the offsets where a crib can be placed in a ciphertext.")
+		fmt.Fprintln(stdout, "  enigma cyclometer   Find the rotor order and Grundstellung from doubled indicators (Rejewski's method).")
+		fmt.Fprintln(stdout, "  enigma zygalski     Find the rotor order and ring setting from the females of the indicators, with Zygalski sheets.")
 		return &parseInfo{isHelp: true}, nil
 	}
 
@@ -55,22 +100,139 @@ func parseArgs(args []string, stdout io.Writer) (*parseInfo, error) {
 		return nil, errors.New("blocksize must be equal or greater than zero")
 	}
 
-	rotors := strings.Split(*rotorsOpt, ",")
-	if len(rotors) != 3 {
-		return nil, errors.New("you should specify 3 rotor ID's")
+	var convention text.Convention
+	if *conventionOpt != "" {
+		var ok bool
+		if convention, ok = text.Conventions[*conventionOpt]; !ok {
+			return nil, errors.New("invalid convention '" + *conventionOpt + "'")
+		}
 	}
 
-	e, err := enigma.WithRotors(rotors[0], rotors[1], rotors[2], *reflectorOpt)
+	formatter, err := parseFormatter(*groupsOpt, *padOpt, *numberOpt, *headerOpt, *timeOpt, *partOpt, *indicatorOpt)
 	if err != nil {
 		return nil, err
 	}
 
-	err = e.Configure(*ringOpt, *windowOpt)
+	if formatter != nil {
+		if *passthroughOpt || *decryptOpt {
+			return nil, errors.New("formatted text cannot be used with passthrough or decrypt")
+		}
+
+		if *blockOpt == 0 {
+			return nil, errors.New("formatted text should have a blocksize greater than zero")
+		}
+
+		formatter.GroupSize = uint(*blockOpt)
+	}
+
+	var settings enigma.Settings
+	if *randomOpt {
+		var seed *int64
+		if getopt.IsSet("seed") {
+			seed = seedOpt
+		}
+
+		if settings, err = randomSettings(*modelOpt, seed); err != nil {
+			return nil, err
+		}
+	} else if *settingsOpt != "" {
+		if settings, err = enigma.ParseSettingsString(*settingsOpt, enigma.AutoNotation); err != nil {
+			return nil, err
+		}
+	} else {
+		rotors := strings.Split(*rotorsOpt, ",")
+		if len(rotors) != 3 {
+			return nil, errors.New("you should specify 3 rotor ID's")
+		}
+
+		settings = enigma.Settings{
+			Reflector: *reflectorOpt,
+			Slow:      rotors[0],
+			Middle:    rotors[1],
+			Fast:      rotors[2],
+			Ring:      *ringOpt,
+			Window:    *windowOpt,
+		}
+	}
+
+	e, err := enigma.WithSettings(settings)
 	if err != nil {
 		return nil, err
 	}
 
-	return &parseInfo{e, *fileOpt, *quietOpt, false, uint(*blockOpt)}, nil
+	// the settings keep the start window, since the offset moves the rotors
+	settings.Ring, settings.Window = e.Ring(), e.Window()
+	e.SeekTo(*offsetOpt)
+
+	return &parseInfo{
+		e:             e,
+		settings:      settings,
+		fileName:      *fileOpt,
+		isQuiet:       *quietOpt,
+		blockSize:     uint(*blockOpt),
+		convention:    convention,
+		isDecrypt:     *decryptOpt,
+		isPassthrough: *passthroughOpt,
+		isBackspace:   *backspaceOpt,
+		formatter:     formatter,
+		isRandomKey:   *randomOpt,
+		offset:        *offsetOpt,
+	}, nil
+}
+
+// parseFormatter returns the formatter specified by the formatting flags, or nil if no formatting should be used
+func parseFormatter(groups int, pad string, number, header bool, clock, part, indicator string) (*text.Formatter, error) {
+	if groups == 0 && pad == "" && !number && !header {
+		return nil, nil
+	}
+
+	if groups < 0 {
+		return nil, errors.New("groups per line must be equal or greater than zero")
+	}
+
+	formatter := &text.Formatter{GroupsPerLine: uint(groups), NumberLines: number}
+
+	switch pad {
+	case "":
+		formatter.Padding = text.NoPadding
+	case "x":
+		formatter.Padding = text.PadWithX
+	case "nulls":
+		formatter.Padding = text.PadWithNulls
+	default:
+		return nil, errors.New("invalid padding '" + pad + "'")
+	}
+
+	if header {
+		if clock == "" {
+			clock = time.Now().Format("1504")
+		}
+
+		h := &text.Header{Time: clock, Indicator: indicator}
+
+		if _, err := fmt.Sscanf(part, "%d/%d", &h.Part, &h.Parts); err != nil || h.Part < 1 || h.Part > h.Parts {
+			return nil, errors.New("invalid part '" + part + "' (ex: 1/2)")
+		}
+
+		formatter.Header = h
+	}
+
+	return formatter, nil
+}
+
+// randomSettings returns a random key for the model, using the seed when it is not nil
+func randomSettings(modelName string, seed *int64) (enigma.Settings, error) {
+	model, ok := enigma.Models[modelName]
+	if !ok {
+		return enigma.Settings{}, errors.New("invalid model '" + modelName + "'")
+	}
+
+	var rng io.Reader
+	if seed != nil {
+		rng = rand.New(rand.NewSource(*seed))
+	}
+
+	return enigma.RandomSettings(rng, model, enigma.DefaultConstraints)
 }
 
 func parseGetopt(args []string) error {
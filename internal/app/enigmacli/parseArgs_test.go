@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/ibraimgm/enigma/text"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,6 +23,16 @@ func TestParseArgsError(t *testing.T) {
 		{[]string{"cmd", "-g", "0YZ"}, "ring settings should be specified using only uppercase letters from 'A' to 'Z'"},
 		{[]string{"cmd", "-w", "XY"}, "window settings should be 3 characters long (ex: AAA)"},
 		{[]string{"cmd", "-w", "0YZ"}, "window settings should be specified using only uppercase letters from 'A' to 'Z'"},
+		{[]string{"cmd", "-c", "X"}, "invalid convention 'X'"},
+		{[]string{"cmd", "--random-key", "--model", "X"}, "invalid model 'X'"},
+		{[]string{"cmd", "--offset", "X"}, "not a valid number: X"},
+		{[]string{"cmd", "-s", "B III II"}, "settings should specify the reflector and 3 rotors (ex: B III II I)"},
+		{[]string{"cmd", "-s", "B III II X AAA AAA"}, "unrecognized rotor ID: 'X'"},
+		{[]string{"cmd", "-l", "-1"}, "groups per line must be equal or greater than zero"},
+		{[]string{"cmd", "--pad", "y"}, "invalid padding 'y'"},
+		{[]string{"cmd", "--header", "--part", "3/2"}, "invalid part '3/2' (ex: 1/2)"},
+		{[]string{"cmd", "-n", "-p"}, "formatted text cannot be used with passthrough or decrypt"},
+		{[]string{"cmd", "-n", "-b", "0"}, "formatted text should have a blocksize greater than zero"},
 	}
 
 	for _, test := range tests {
@@ -50,4 +61,68 @@ func TestParseArgsOK(t *testing.T) {
 	assert.False(t, info.isHelp)
 	assert.Equal(t, info.blockSize, uint(3))
 	assert.Equal(t, "a.out", info.fileName)
+	assert.False(t, info.isPassthrough)
+	assert.False(t, info.isBackspace)
+
+	info, err = parseArgs([]string{"cmd", "-p", "--backspace"}, nil)
+	assert.NoError(t, err)
+	assert.True(t, info.isPassthrough)
+	assert.True(t, info.isBackspace)
+}
+
+func TestParseArgsConvention(t *testing.T) {
+	info, err := parseArgs([]string{"cmd", "-c", "heer", "-d"}, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, info)
+
+	assert.Equal(t, "heer", info.convention.Name())
+	assert.True(t, info.isDecrypt)
+	assert.False(t, info.isPassthrough)
+}
+
+func TestParseArgsFormatter(t *testing.T) {
+	info, err := parseArgs([]string{"cmd"}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, info.formatter)
+
+	info, err = parseArgs([]string{"cmd", "-b", "4", "-l", "10", "--pad", "nulls", "--header", "--time", "1230", "--part", "2/3", "--indicator", "WZE HPN"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &text.Formatter{
+		GroupSize:     4,
+		GroupsPerLine: 10,
+		Padding:       text.PadWithNulls,
+		Header:        &text.Header{Time: "1230", Parts: 3, Part: 2, Indicator: "WZE HPN"},
+	}, info.formatter)
+}
+
+func TestParseArgsSettings(t *testing.T) {
+	info, err := parseArgs([]string{"cmd", "-r", "I,II,III", "-s", "C V IV III 01 02 03 XYZ AB CD"}, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "C", info.e.Reflector())
+	assert.Equal(t, "V", info.e.Slow())
+	assert.Equal(t, "IV", info.e.Middle())
+	assert.Equal(t, "III", info.e.Fast())
+	assert.Equal(t, "ABC", info.e.Ring())
+	assert.Equal(t, "XYZ", info.e.Window())
+	assert.Equal(t, "ABCD", info.settings.Plugs)
+}
+
+func TestParseArgsRandomKey(t *testing.T) {
+	info, err := parseArgs([]string{"cmd", "--random-key", "--model", "I", "--seed", "42"}, nil)
+	assert.NoError(t, err)
+	assert.True(t, info.isRandomKey)
+	assert.Len(t, info.settings.Plugs, 20)
+	assert.Contains(t, []string{"I", "II", "III", "IV", "V"}, info.e.Fast())
+
+	other, err := parseArgs([]string{"cmd", "--random-key", "--model", "I", "--seed", "42"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, info.settings, other.settings)
+
+	// zero is a seed like any other
+	info, err = parseArgs([]string{"cmd", "--random-key", "--seed", "0"}, nil)
+	assert.NoError(t, err)
+	other, err = parseArgs([]string{"cmd", "--random-key", "--seed", "0"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, info.settings, other.settings)
 }
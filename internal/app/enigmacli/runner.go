@@ -2,11 +2,29 @@ package enigmacli
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"os"
 )
 
+// subcommands are the alternative modes of the command-line interface, selected by the first argument
+var subcommands = map[string]func(args []string, stdin io.Reader, stdout io.Writer) error{
+	"batch":      runBatchMode,
+	"bombe":      runBombeMode,
+	"crack":      runCrackMode,
+	"crib":       runCribMode,
+	"cyclometer": runCyclometerMode,
+	"zygalski":   runZygalskiMode,
+}
+
 // Run is the main entry point for the command-line enigma interface
 func Run() error {
+	if len(os.Args) > 1 {
+		if subcommand, ok := subcommands[os.Args[1]]; ok {
+			return subcommand(os.Args[1:], os.Stdin, os.Stdout)
+		}
+	}
+
 	info, err := parseArgs(os.Args, os.Stdout)
 	if err != nil {
 		return err
@@ -16,6 +34,10 @@ func Run() error {
 		return nil
 	}
 
+	if info.isRandomKey {
+		fmt.Fprintf(os.Stderr, "=> Random key: %s\n", info.settings)
+	}
+
 	outputFile := os.Stdout
 
 	if info.fileName != "" {
@@ -29,5 +51,9 @@ func Run() error {
 		defer outputFile.Flush()
 	}
 
+	if info.formatter != nil {
+		return runFormattedMode(info, os.Stdin, os.Stdout, outputFile)
+	}
+
 	return runNormalMode(info, os.Stdin, os.Stdout, outputFile)
 }
@@ -0,0 +1,179 @@
+package enigmacli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ibraimgm/enigma/cryptanalysis/zygalski"
+	"github.com/ibraimgm/enigma/machine/enigma"
+	getopt "github.com/pborman/getopt/v2"
+)
+
+type zygalskiInfo struct {
+	isHelp    bool
+	isQuiet   bool
+	isSVG     bool
+	reflector string
+	orders    [][3]string
+	sheetsDir string
+	cellSize  int
+}
+
+// parseZygalskiArgs parse the command line arguments of the 'zygalski' subcommand
+func parseZygalskiArgs(args []string, stdout io.Writer) (*zygalskiInfo, error) {
+	getopt.CommandLine = getopt.New()
+	helpFlag := getopt.BoolLong("help", 'h', "Show usage and exit")
+	modelOpt := getopt.StringLong("model", 'm', "I", "Enigma model, whose rotors are tested in every order (I or M3).", "I")
+	rotorsOpt := getopt.StringLong("rotors", 'r', "", "Comma-separated list of rotors, to test a single rotor order.", "III,II,I")
+	reflectorOpt := getopt.StringLong("reflector", 'f', "B", "Reflector to use.", "B")
+	sheetsOpt := getopt.StringLong("sheets", 0, "", "Write the 26 sheets of the rotor order ('-r') to a directory, instead of stacking them.", "DIR")
+	svgOpt := getopt.BoolLong("svg", 0, "Write the sheets as SVG instead of PNG.")
+	cellOpt := getopt.IntLong("cell", 0, 8, "Size of each square of the sheets, in pixels.", "8")
+	quietOpt := getopt.BoolLong("quiet", 'q', "Do not print standard banner.")
+
+	if err := parseGetopt(args); err != nil {
+		return nil, err
+	}
+
+	if *helpFlag {
+		getopt.PrintUsage(stdout)
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Reads the messages of a day from stdin until EOF is reached, one per line, with the Grundstellung and the")
+		fmt.Fprintln(stdout, "doubled indicator (ex: 'RTJ WAHWIK'), and stacks the Zygalski sheets of the females to find the rotor order")
+		fmt.Fprintln(stdout, "and the ring setting. With '--sheets', the sheets of a rotor order are written as image files instead.")
+		return &zygalskiInfo{isHelp: true}, nil
+	}
+
+	info := &zygalskiInfo{
+		isQuiet:   *quietOpt,
+		isSVG:     *svgOpt,
+		reflector: *reflectorOpt,
+		sheetsDir: *sheetsOpt,
+		cellSize:  *cellOpt,
+	}
+
+	if info.cellSize <= 0 {
+		return nil, errors.New("the cell size should be greater than zero")
+	}
+
+	if *rotorsOpt != "" {
+		rotors := strings.Split(*rotorsOpt, ",")
+		if len(rotors) != 3 {
+			return nil, errors.New("you should specify 3 rotor ID's")
+		}
+
+		info.orders = [][3]string{{rotors[0], rotors[1], rotors[2]}}
+	} else if info.sheetsDir != "" {
+		return nil, errors.New("you should specify the rotor order of the sheets")
+	} else {
+		model, ok := enigma.Models[*modelOpt]
+		if !ok {
+			return nil, errors.New("invalid model '" + *modelOpt + "'")
+		}
+
+		info.orders = model.Orders()
+	}
+
+	return info, nil
+}
+
+func runZygalskiMode(args []string, stdin io.Reader, stdout io.Writer) error {
+	info, err := parseZygalskiArgs(args, stdout)
+	if err != nil || info.isHelp {
+		return err
+	}
+
+	if info.sheetsDir != "" {
+		return writeSheets(info, stdout)
+	}
+
+	messages := []zygalski.Message{}
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		m, err := zygalski.ParseMessage(scanner.Text())
+		if err != nil {
+			return err
+		}
+
+		messages = append(messages, m)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if !info.isQuiet {
+		fmt.Fprintf(stdout, "=> Messages: \t%d\n", len(messages))
+		fmt.Fprintf(stdout, "=>  Females: \t%d\n", zygalski.Females(messages))
+		fmt.Fprintf(stdout, "=>   Orders: \t%d\n", len(info.orders))
+		fmt.Fprintln(stdout, "--- Running in 'zygalski' mode ---")
+	}
+
+	solutions, err := zygalski.Solve(info.reflector, info.orders, messages)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range solutions {
+		fmt.Fprintf(stdout, "%s %s %s %s %s\n", s.Reflector, s.Slow, s.Middle, s.Fast, s.Ring)
+	}
+
+	if !info.isQuiet {
+		fmt.Fprintf(stdout, "--- %d solution(s) found ---\n", len(solutions))
+	}
+
+	return nil
+}
+
+// writeSheets writes the sheets of the rotor order as image files
+func writeSheets(info *zygalskiInfo, stdout io.Writer) error {
+	sheets, err := zygalski.Sheets(info.reflector, info.orders[0])
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(info.sheetsDir, 0755); err != nil {
+		return err
+	}
+
+	for _, s := range sheets {
+		ext := "png"
+		if info.isSVG {
+			ext = "svg"
+		}
+
+		name := filepath.Join(info.sheetsDir, fmt.Sprintf("%s-%s-%s-%s-%c.%s", s.Reflector, s.Slow, s.Middle, s.Fast, 'A'+s.Position, ext))
+		if err := writeSheet(s, name, info); err != nil {
+			return err
+		}
+
+		if !info.isQuiet {
+			fmt.Fprintln(stdout, name)
+		}
+	}
+
+	return nil
+}
+
+func writeSheet(s *zygalski.Sheet, name string, info *zygalskiInfo) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if info.isSVG {
+		return s.WriteSVG(f, info.cellSize)
+	}
+
+	return s.WritePNG(f, info.cellSize)
+}
@@ -0,0 +1,76 @@
+package enigmacli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseZygalskiArgs(t *testing.T) {
+	info, err := parseZygalskiArgs([]string{"zygalski"}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, info.orders, 60)
+	assert.Equal(t, 8, info.cellSize)
+
+	info, err = parseZygalskiArgs([]string{"zygalski", "-r", "II,I,III", "--sheets", "out", "--svg", "--cell", "4", "-q"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, [][3]string{{"II", "I", "III"}}, info.orders)
+	assert.Equal(t, "out", info.sheetsDir)
+	assert.True(t, info.isSVG)
+	assert.Equal(t, 4, info.cellSize)
+	assert.True(t, info.isQuiet)
+
+	var tests = []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"zygalski", "-r", "I,II"}, "you should specify 3 rotor ID's"},
+		{[]string{"zygalski", "-m", "X"}, "invalid model 'X'"},
+		{[]string{"zygalski", "--sheets", "out"}, "you should specify the rotor order of the sheets"},
+		{[]string{"zygalski", "--cell", "0"}, "the cell size should be greater than zero"},
+	}
+
+	for _, test := range tests {
+		_, err = parseZygalskiArgs(test.args, nil)
+		assert.EqualError(t, err, test.expected)
+	}
+
+	stdout := &strings.Builder{}
+	info, err = parseZygalskiArgs([]string{"zygalski", "-h"}, stdout)
+	assert.NoError(t, err)
+	assert.True(t, info.isHelp)
+	assert.Contains(t, stdout.String(), "stacks the Zygalski sheets")
+}
+
+func TestZygalskiModeOK(t *testing.T) {
+	stdout := &strings.Builder{}
+	input := "RTJ WAHWIK\n\nDQY PLAPLM\nHJK XXWXYZ\n"
+	err := runZygalskiMode([]string{"zygalski", "-r", "I,II,III"}, strings.NewReader(input), stdout)
+	assert.NoError(t, err)
+	assert.Contains(t, stdout.String(), "=> Messages: \t3\n=>  Females: \t4\n")
+	assert.Contains(t, stdout.String(), "solution(s) found ---\n")
+
+	dir := filepath.Join(t.TempDir(), "sheets")
+	stdout = &strings.Builder{}
+	err = runZygalskiMode([]string{"zygalski", "-r", "I,II,III", "--sheets", dir, "--svg", "--cell", "2"}, nil, stdout)
+	assert.NoError(t, err)
+	assert.Equal(t, 26, strings.Count(stdout.String(), "\n"))
+
+	files, _ := os.ReadDir(dir)
+	assert.Len(t, files, 26)
+	assert.Equal(t, "B-I-II-III-A.svg", files[0].Name())
+}
+
+func TestZygalskiModeError(t *testing.T) {
+	err := runZygalskiMode([]string{"zygalski"}, &mockReader{}, &strings.Builder{})
+	assert.EqualError(t, err, "some I/O error happened")
+
+	err = runZygalskiMode([]string{"zygalski"}, strings.NewReader("RTJ WAH"), &strings.Builder{})
+	assert.Error(t, err)
+
+	err = runZygalskiMode([]string{"zygalski"}, strings.NewReader("RTJ WAHXYZ"), &strings.Builder{})
+	assert.EqualError(t, err, "the messages should have at least one female")
+}
@@ -0,0 +1,18 @@
+// Package count has a writer that counts the bytes written through it, used by the WriteTo methods of the files
+// saved by the enigma and cryptanalysis packages.
+package count
+
+import "io"
+
+// Writer writes to W, adding the number of bytes written to N.
+type Writer struct {
+	W io.Writer
+	N int64
+}
+
+func (cw *Writer) Write(p []byte) (int, error) {
+	n, err := cw.W.Write(p)
+	cw.N += int64(n)
+
+	return n, err
+}
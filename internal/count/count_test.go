@@ -0,0 +1,21 @@
+package count_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/ibraimgm/enigma/internal/count"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	cw := &count.Writer{W: &buf}
+
+	fmt.Fprint(cw, "ENIGMA")
+	fmt.Fprint(cw, "XY")
+
+	assert.Equal(t, int64(8), cw.N)
+	assert.Equal(t, "ENIGMAXY", buf.String())
+}
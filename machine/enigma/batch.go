@@ -0,0 +1,103 @@
+package enigma
+
+import (
+	"runtime"
+)
+
+// Job is a message to be encoded in a batch, with its own settings.
+type Job struct {
+	Settings Settings
+	Text     string
+}
+
+// Result is the encoded text of a job, or the error that prevented it from being encoded (usually, invalid
+// settings).
+type Result struct {
+	// Index is the position of the job in the batch, starting at 0.
+	Index int
+	Text  string
+	Err   error
+}
+
+// BatchOptions controls the encoding of a batch.
+type BatchOptions struct {
+	// Workers is the maximum number of jobs encoded at the same time. Zero means the number of CPUs.
+	Workers int
+	// Encode are the options used to encode the text of every job.
+	Encode EncodeOptions
+}
+
+// EncodeBatch encodes every job, with at most options.Workers jobs at the same time, and returns the results in the
+// order of the jobs. A job with invalid settings does not stop the other ones; its error is in its result.
+func EncodeBatch(jobs []Job, options BatchOptions) []Result {
+	input := make(chan Job)
+	go func() {
+		defer close(input)
+
+		for _, job := range jobs {
+			input <- job
+		}
+	}()
+
+	results := make([]Result, 0, len(jobs))
+	for r := range EncodeJobs(input, options) {
+		results = append(results, r)
+	}
+
+	return results
+}
+
+// EncodeJobs encodes the jobs received from the channel, with at most options.Workers jobs at the same time, and
+// sends the results to the returned channel, in the order of the jobs. The returned channel is closed after the
+// jobs channel is closed and every result is sent.
+//
+// The results must be received until the channel is closed: at most options.Workers jobs are taken from the jobs
+// channel before their results are received.
+func EncodeJobs(jobs <-chan Job, options BatchOptions) <-chan Result {
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// the results of the jobs being encoded, in order
+	pending := make(chan chan Result, workers-1)
+	results := make(chan Result)
+
+	go func() {
+		defer close(pending)
+
+		pool := NewPool()
+		i := 0
+
+		for job := range jobs {
+			r := make(chan Result, 1)
+			pending <- r
+
+			go func(i int, job Job) {
+				r <- encodeJob(pool, i, job, options.Encode)
+			}(i, job)
+
+			i++
+		}
+	}()
+
+	go func() {
+		defer close(results)
+
+		for r := range pending {
+			results <- <-r
+		}
+	}()
+
+	return results
+}
+
+func encodeJob(pool *Pool, i int, job Job, options EncodeOptions) Result {
+	e, err := pool.Get(job.Settings)
+	if err != nil {
+		return Result{Index: i, Err: err}
+	}
+	defer pool.Put(e)
+
+	return Result{Index: i, Text: e.EncodeMessageWith(job.Text, options)}
+}
@@ -0,0 +1,73 @@
+package enigma_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+func batchJobs() []enigma.Job {
+	jobs := []enigma.Job{}
+
+	for i := 0; i < 100; i++ {
+		s := steppingSettings[i%len(steppingSettings)]
+		s.Window = enigma.PositionToWindow(i * 97)
+		jobs = append(jobs, enigma.Job{Settings: s, Text: fmt.Sprintf("message number %d", i)})
+	}
+
+	return jobs
+}
+
+func TestEncodeBatch(t *testing.T) {
+	jobs := batchJobs()
+	jobs[10].Settings.Slow = "X"
+	jobs[20].Settings.Window = "AB"
+
+	for _, workers := range []int{0, 1, 3} {
+		results := enigma.EncodeBatch(jobs, enigma.BatchOptions{Workers: workers, Encode: enigma.EncodeOptions{BlockSize: 5}})
+		assert.Len(t, results, len(jobs))
+
+		for i, r := range results {
+			assert.Equal(t, i, r.Index)
+
+			e, err := enigma.WithSettings(jobs[i].Settings)
+			if err != nil {
+				assert.EqualError(t, r.Err, err.Error())
+				assert.Equal(t, "", r.Text)
+				continue
+			}
+
+			assert.NoError(t, r.Err)
+			assert.Equal(t, e.EncodeMessage(jobs[i].Text, 5), r.Text, "job %d", i)
+		}
+	}
+
+	assert.Empty(t, enigma.EncodeBatch(nil, enigma.BatchOptions{}))
+}
+
+func TestEncodeJobs(t *testing.T) {
+	jobs := batchJobs()
+	input := make(chan enigma.Job)
+
+	go func() {
+		defer close(input)
+
+		for _, job := range jobs {
+			input <- job
+		}
+	}()
+
+	i := 0
+	for r := range enigma.EncodeJobs(input, enigma.BatchOptions{Workers: 4}) {
+		e, _ := enigma.WithSettings(jobs[i].Settings)
+
+		assert.Equal(t, i, r.Index)
+		assert.NoError(t, r.Err)
+		assert.Equal(t, e.EncodeMessage(jobs[i].Text, 0), r.Text)
+		i++
+	}
+
+	assert.Equal(t, len(jobs), i)
+}
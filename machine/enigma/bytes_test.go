@@ -0,0 +1,105 @@
+package enigma_test
+
+import (
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/ibraimgm/enigma/machine/parts"
+	"github.com/stretchr/testify/assert"
+)
+
+// machines returns a default machine and its compiled version, with the same settings
+func machines(t *testing.T) []enigma.Enigma {
+	e, err := enigma.WithSettings(enigma.Settings{Reflector: "B", Slow: "III", Middle: "II", Fast: "I", Ring: "SKY", Window: "RIM", Plugs: "AZ"})
+	assert.NoError(t, err)
+
+	c, err := enigma.Compile(e)
+	assert.NoError(t, err)
+
+	return []enigma.Enigma{e, c}
+}
+
+func TestEncodeBytes(t *testing.T) {
+	for _, e := range machines(t) {
+		expected := e.EncodeMessage("Hello, World!", 0)
+		e.Configure("SKY", "RIM")
+
+		dst := make([]byte, 20)
+		n := e.EncodeBytes(dst, []byte("Hello, World!"))
+		assert.Equal(t, expected, string(dst[:n]))
+
+		// in place
+		e.Configure("SKY", "RIM")
+		buf := []byte("Hello, World!")
+		n = e.EncodeBytes(buf, buf)
+		assert.Equal(t, expected, string(buf[:n]))
+
+		// stops when dst is full
+		e.Configure("SKY", "RIM")
+		n = e.EncodeBytes(dst[:3], []byte("Hello, World!"))
+		assert.Equal(t, 3, n)
+		assert.Equal(t, expected[:3], string(dst[:n]))
+		assert.Equal(t, "RIP", e.Window())
+	}
+}
+
+func TestEncodeSignals(t *testing.T) {
+	for _, e := range machines(t) {
+		expected := e.EncodeMessage("HELLO", 0)
+		e.Configure("SKY", "RIM")
+
+		src := []parts.Signal{8, 0, 5, 12, 27, 12, 15}
+		dst := make([]parts.Signal, len(src))
+		n := e.EncodeSignals(dst, src)
+		assert.Equal(t, 5, n)
+
+		for i, s := range dst[:n] {
+			assert.Equal(t, expected[i], byte('A'+s-1))
+		}
+	}
+}
+
+func TestEncodeBytesAllocs(t *testing.T) {
+	src := []byte(benchmarkMessage)
+	dst := make([]byte, len(src))
+	signals := make([]parts.Signal, len(src))
+
+	for _, e := range machines(t) {
+		assert.Equal(t, 0.0, testing.AllocsPerRun(100, func() { e.EncodeBytes(dst, src) }))
+		assert.Equal(t, 0.0, testing.AllocsPerRun(100, func() { e.EncodeSignals(signals, signals) }))
+	}
+}
+
+func BenchmarkEncodeMessage(b *testing.B) {
+	e := enigma.WithDefaults()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkMessage)))
+
+	for i := 0; i < b.N; i++ {
+		e.EncodeMessage(benchmarkMessage, 5)
+	}
+}
+
+func BenchmarkEncodeBytes(b *testing.B) {
+	e := enigma.WithDefaults()
+	src := []byte(benchmarkMessage)
+	dst := make([]byte, len(src))
+	b.ReportAllocs()
+	b.SetBytes(int64(len(src)))
+
+	for i := 0; i < b.N; i++ {
+		e.EncodeBytes(dst, src)
+	}
+}
+
+func BenchmarkCompiledEncodeBytes(b *testing.B) {
+	e, _ := enigma.Compile(enigma.WithDefaults())
+	src := []byte(benchmarkMessage)
+	dst := make([]byte, len(src))
+	b.ReportAllocs()
+	b.SetBytes(int64(len(src)))
+
+	for i := 0; i < b.N; i++ {
+		e.EncodeBytes(dst, src)
+	}
+}
@@ -0,0 +1,205 @@
+package enigma
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ibraimgm/enigma/internal/count"
+)
+
+// Positions is the number of rotor positions of a 3-rotor machine.
+// A position is a number from 0 ('AAA') to Positions-1 ('ZZZ'), with the slow rotor as the most significant digit.
+const Positions = 26 * 26 * 26
+
+// PositionToWindow returns the window setting of a position (ex: 0 is "AAA", 27 is "ABB").
+func PositionToWindow(p int) string {
+	return string([]byte{byte('A' + p/676), byte('A' + p/26%26), byte('A' + p%26)})
+}
+
+// WindowToPosition returns the position of a window setting (ex: "ABB" is 27).
+func WindowToPosition(window string) (int, error) {
+	runes, err := parseLetters(window, "window")
+	if err != nil {
+		return 0, err
+	}
+
+	return int(runes[0]-'A')*676 + int(runes[1]-'A')*26 + int(runes[2]-'A'), nil
+}
+
+// PermutationCache holds, for a machine with fixed rotors, reflector, ring settings and plugboard, the permutation
+// of the 26 letters made by a keypress at each of the Positions, and the position after each keypress.
+// With it, a text can be encoded from any start position with table lookups only, which is what most searches do.
+//
+// A cache needs 26 bytes for the permutation and 2 bytes for the next position of each rotor position, a total of
+// 492,128 bytes (about 480 KB). It can be saved with WriteTo and read back with ReadPermutationCache, and is safe for
+// concurrent use, since it is never changed after it is built.
+type PermutationCache struct {
+	settings Settings
+	next     []uint16
+	perm     [][26]byte
+}
+
+// NewPermutationCache builds the cache of a machine with the settings. The window setting is ignored.
+func NewPermutationCache(settings Settings) (*PermutationCache, error) {
+	settings.Window = ""
+	settings.Ring = orDefault(settings.Ring)
+
+	e, err := WithSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := compile(e.(*enigmaImpl))
+	if err != nil {
+		return nil, err
+	}
+
+	cache := &PermutationCache{settings: settings, next: make([]uint16, Positions), perm: make([][26]byte, Positions)}
+
+	for p := 0; p < Positions; p++ {
+		window := [3]int{p / 676, p / 26 % 26, p % 26}
+
+		for x := 0; x < 26; x++ {
+			c.window = window
+			cache.perm[p][x] = c.encode(uint8(x))
+		}
+
+		cache.next[p] = uint16(c.window[0]*676 + c.window[1]*26 + c.window[2])
+	}
+
+	return cache, nil
+}
+
+// Settings returns the settings of the machine of the cache, without the window setting.
+func (cache *PermutationCache) Settings() Settings {
+	return cache.settings
+}
+
+// Next returns the position of the rotors after a keypress from position p.
+func (cache *PermutationCache) Next(p int) int {
+	return int(cache.next[p])
+}
+
+// Permutation returns the encoding of each letter (from 0 to 25) in a keypress from position p. The rotors step
+// before the letter is encoded, as in the machine, so this is the encoding with the rotors at Next(p).
+// The returned array should not be changed.
+func (cache *PermutationCache) Permutation(p int) *[26]byte {
+	return &cache.perm[p]
+}
+
+// Encode encodes the letters of src into dst, from the start position, just like the EncodeBytes method of a machine
+// with the same settings and window. It returns the number of bytes written and the position after the last
+// keypress.
+func (cache *PermutationCache) Encode(dst, src []byte, start int) (n int, end int) {
+	p := start
+
+	for _, b := range src {
+		if n == len(dst) {
+			break
+		}
+
+		switch {
+		case b >= 'A' && b <= 'Z':
+			dst[n] = 'A' + cache.perm[p][b-'A']
+		case b >= 'a' && b <= 'z':
+			dst[n] = 'A' + cache.perm[p][b-'a']
+		default:
+			continue
+		}
+
+		p = int(cache.next[p])
+		n++
+	}
+
+	return n, p
+}
+
+type cacheFile struct {
+	Settings Settings
+	Next     []uint16
+	Perm     []byte
+}
+
+// WriteTo saves the cache, in a format that can be read with ReadPermutationCache.
+func (cache *PermutationCache) WriteTo(w io.Writer) (int64, error) {
+	perm := make([]byte, 0, Positions*26)
+	for p := range cache.perm {
+		perm = append(perm, cache.perm[p][:]...)
+	}
+
+	cw := &count.Writer{W: w}
+	err := gob.NewEncoder(cw).Encode(cacheFile{cache.settings, cache.next, perm})
+
+	return cw.N, err
+}
+
+// ReadPermutationCache reads a cache saved with WriteTo.
+func ReadPermutationCache(r io.Reader) (*PermutationCache, error) {
+	var f cacheFile
+
+	if err := gob.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+
+	if len(f.Next) != Positions || len(f.Perm) != Positions*26 {
+		return nil, errors.New("invalid permutation cache: wrong number of positions")
+	}
+
+	cache := &PermutationCache{settings: f.Settings, next: f.Next, perm: make([][26]byte, Positions)}
+
+	for p := range cache.perm {
+		copy(cache.perm[p][:], f.Perm[p*26:])
+
+		if int(cache.next[p]) >= Positions {
+			return nil, errors.New("invalid permutation cache: wrong next position")
+		}
+	}
+
+	return cache, nil
+}
+
+// LoadPermutationCache reads the cache saved in the file name, if it exists and has the same settings (except for
+// the window setting). Otherwise, the cache is built and saved in the file, creating the parent directories if
+// needed.
+func LoadPermutationCache(name string, settings Settings) (*PermutationCache, error) {
+	settings.Window = ""
+	settings.Ring = orDefault(settings.Ring)
+
+	if f, err := os.Open(name); err == nil {
+		cache, err := ReadPermutationCache(f)
+		f.Close()
+
+		if err == nil && cache.settings == settings {
+			return cache, nil
+		}
+	}
+
+	cache, err := NewPermutationCache(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := cache.WriteTo(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	// a failed close can leave the file truncated
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	return cache, nil
+}
@@ -0,0 +1,108 @@
+package enigma_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+var cacheSettings = enigma.Settings{Reflector: "B", Slow: "VI", Middle: "II", Fast: "VIII", Ring: "BUL", Plugs: "AZQW"}
+
+func TestPositions(t *testing.T) {
+	assert.Equal(t, "AAA", enigma.PositionToWindow(0))
+	assert.Equal(t, "ABB", enigma.PositionToWindow(27))
+	assert.Equal(t, "ZZZ", enigma.PositionToWindow(enigma.Positions-1))
+
+	p, err := enigma.WindowToPosition("ABB")
+	assert.NoError(t, err)
+	assert.Equal(t, 27, p)
+
+	_, err = enigma.WindowToPosition("abc")
+	assert.Error(t, err)
+}
+
+func TestPermutationCache(t *testing.T) {
+	cache, err := enigma.NewPermutationCache(cacheSettings)
+	assert.NoError(t, err)
+	assert.Equal(t, "", cache.Settings().Window)
+
+	e, _ := enigma.WithSettings(cacheSettings)
+	src := []byte(benchmarkMessage)
+	dst := make([]byte, len(src))
+
+	for _, window := range []string{"AAA", "AEQ", "ZMY", "QDR"} {
+		e.SetWindow(window)
+		expected := e.EncodeMessage(benchmarkMessage, 0)
+
+		start, _ := enigma.WindowToPosition(window)
+		n, end := cache.Encode(dst, src, start)
+		assert.Equal(t, expected, string(dst[:n]))
+		assert.Equal(t, e.Window(), enigma.PositionToWindow(end))
+	}
+
+	// the permutations are involutions, without fixed letters
+	p, _ := enigma.WindowToPosition("QDR")
+	perm := cache.Permutation(p)
+	for x := range perm {
+		assert.NotEqual(t, byte(x), perm[x])
+		assert.Equal(t, byte(x), perm[perm[x]])
+	}
+
+	_, err = enigma.NewPermutationCache(enigma.Settings{Reflector: "B", Slow: "I", Middle: "II", Fast: "IX"})
+	assert.EqualError(t, err, "unrecognized rotor ID: 'IX'")
+}
+
+func TestPermutationCacheWriteTo(t *testing.T) {
+	cache, _ := enigma.NewPermutationCache(cacheSettings)
+
+	var buf bytes.Buffer
+	n, err := cache.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	read, err := enigma.ReadPermutationCache(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, cache, read)
+
+	_, err = enigma.ReadPermutationCache(bytes.NewReader([]byte("garbage")))
+	assert.Error(t, err)
+}
+
+func TestLoadPermutationCache(t *testing.T) {
+	dir, err := os.MkdirTemp("", "enigma")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "sub", "cache.gob")
+	cache, err := enigma.LoadPermutationCache(name, cacheSettings)
+	assert.NoError(t, err)
+	assert.FileExists(t, name)
+
+	loaded, err := enigma.LoadPermutationCache(name, cacheSettings)
+	assert.NoError(t, err)
+	assert.Equal(t, cache, loaded)
+
+	// different settings rebuild the file
+	other := cacheSettings
+	other.Ring = "AAA"
+	loaded, err = enigma.LoadPermutationCache(name, other)
+	assert.NoError(t, err)
+	assert.Equal(t, "AAA", loaded.Settings().Ring)
+	assert.NotEqual(t, cache.Permutation(0), loaded.Permutation(0))
+}
+
+func BenchmarkPermutationCacheEncode(b *testing.B) {
+	cache, _ := enigma.NewPermutationCache(cacheSettings)
+	src := []byte(benchmarkMessage)
+	dst := make([]byte, len(src))
+	b.ReportAllocs()
+	b.SetBytes(int64(len(src)))
+
+	for i := 0; i < b.N; i++ {
+		cache.Encode(dst, src, i%enigma.Positions)
+	}
+}
@@ -0,0 +1,293 @@
+package enigma
+
+import (
+	"errors"
+
+	"github.com/ibraimgm/enigma/machine/parts"
+)
+
+// compiledImpl is an enigma machine with every part replaced by lookup tables.
+// The fast rotor is kept in two tables (forward and reverse) with its encoding for each of the 26 offsets between
+// the window and the ring settings, and the path through the middle rotor, the slow rotor and the reflector (and back)
+// is a single permutation for each of the 676 offsets of these rotors. This needs about 19 KB per machine and
+// does not depend on the ring settings, so changing the ring or window settings is free.
+type compiledImpl struct {
+	ids     [4]string
+	plug    [26]uint8
+	fast    [26][26]uint8
+	fastRev [26][26]uint8
+	inner   [676][26]uint8
+	ring    [3]int
+	// start is the last window setting, and keypresses is the number of keypresses since it was set
+	start      [3]int
+	keypresses uint64
+	rotorStack
+}
+
+// Compile builds a faster version of the machine, for uses like cryptanalysis that encode billions of letters.
+// The compiled machine has the same parts and settings of e and produces the same output, but encodes each letter
+// with table lookups and integer arithmetic only. It is independent from e: encoding with one does not move the
+// rotors of the other.
+//
+// Only machines built by this package with the default keyboard and lightboard, and with parts that report their
+// wiring (as every part of the parts package does) can be compiled. A Synchronized machine is compiled into a
+// machine that is not synchronized.
+func Compile(e Enigma) (Enigma, error) {
+	switch m := e.(type) {
+	case *compiledImpl:
+		c := *m
+		return &c, nil
+	case *enigmaImpl:
+		return compile(m)
+	case *Synchronized:
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		return Compile(m.e)
+	default:
+		return nil, errors.New("only machines built by the enigma package can be compiled")
+	}
+}
+
+func compile(e *enigmaImpl) (*compiledImpl, error) {
+	if e.keyboard != parts.DefaultKeyboard || e.lightboard != parts.DefaultLightboard {
+		return nil, errors.New("only machines with the default keyboard and lightboard can be compiled")
+	}
+
+	rotors := [3]parts.WiredRotor{}
+	for i, r := range []parts.Rotor{e.slow, e.middle, e.fast} {
+		wired, ok := r.(parts.WiredRotor)
+		if !ok {
+			return nil, errors.New("rotor '" + r.ID() + "' does not report its wiring")
+		}
+
+		rotors[i] = wired
+	}
+
+	reflector, ok := e.reflector.(parts.Wired)
+	if !ok {
+		return nil, errors.New("reflector '" + e.reflector.ID() + "' does not report its wiring")
+	}
+
+	plugboard, ok := e.plugboard.(parts.Wired)
+	if !ok {
+		return nil, errors.New("the plugboard does not report its wiring")
+	}
+
+	c := &compiledImpl{ids: [4]string{e.reflector.ID(), e.slow.ID(), e.middle.ID(), e.fast.ID()}}
+
+	for x, v := range plugboard.Wiring() {
+		c.plug[x] = uint8(v - 1)
+	}
+
+	for i, r := range rotors {
+		for _, n := range r.Notches() {
+			if n >= 'A' && n <= 'Z' {
+				c.notches[i][n-'A'] = true
+			}
+		}
+	}
+
+	c.fast, c.fastRev = shiftedWiring(rotors[2].Wiring())
+	slow, slowRev := shiftedWiring(rotors[0].Wiring())
+	middle, middleRev := shiftedWiring(rotors[1].Wiring())
+	refl := reflector.Wiring()
+
+	for s := 0; s < 26; s++ {
+		for m := 0; m < 26; m++ {
+			for x := range c.inner[s*26+m] {
+				y := slow[s][middle[m][x]]
+				y = uint8(refl[y] - 1)
+				c.inner[s*26+m][x] = middleRev[m][slowRev[s][y]]
+			}
+		}
+	}
+
+	if err := c.Configure(e.Ring(), e.Window()); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// shiftedWiring returns the forward and reverse encoding of a rotor, for each offset between its window and ring
+// settings
+func shiftedWiring(wiring [26]parts.Signal) (forward, reverse [26][26]uint8) {
+	for shift := 0; shift < 26; shift++ {
+		for x := 0; x < 26; x++ {
+			y := (int(wiring[(x+shift)%26]-1) - shift + 26) % 26
+			forward[shift][x] = uint8(y)
+			reverse[shift][y] = uint8(x)
+		}
+	}
+
+	return forward, reverse
+}
+
+func (c *compiledImpl) Reflector() string {
+	return c.ids[0]
+}
+
+func (c *compiledImpl) Slow() string {
+	return c.ids[1]
+}
+
+func (c *compiledImpl) Middle() string {
+	return c.ids[2]
+}
+
+func (c *compiledImpl) Fast() string {
+	return c.ids[3]
+}
+
+func (c *compiledImpl) Window() string {
+	return string([]rune{rune('A' + c.window[0]), rune('A' + c.window[1]), rune('A' + c.window[2])})
+}
+
+func (c *compiledImpl) SetWindow(settings string) error {
+	runes, err := parseLetters(settings, "window")
+	if err != nil {
+		return err
+	}
+
+	for i, r := range runes {
+		c.window[i] = int(r - 'A')
+	}
+
+	c.start = c.window
+	c.keypresses = 0
+	return nil
+}
+
+func (c *compiledImpl) Ring() string {
+	return string([]rune{rune('A' + c.ring[0]), rune('A' + c.ring[1]), rune('A' + c.ring[2])})
+}
+
+func (c *compiledImpl) SetRing(settings string) error {
+	runes, err := parseLetters(settings, "ring")
+	if err != nil {
+		return err
+	}
+
+	for i, r := range runes {
+		c.ring[i] = int(r - 'A')
+	}
+
+	return nil
+}
+
+func (c *compiledImpl) Configure(ringSetting, windowSetting string) error {
+	if err := c.SetRing(ringSetting); err != nil {
+		return err
+	}
+
+	return c.SetWindow(windowSetting)
+}
+
+func (c *compiledImpl) Encode(input rune) (rune, bool) {
+	switch {
+	case input >= 'A' && input <= 'Z':
+		return rune('A' + c.encode(uint8(input-'A'))), true
+	case input >= 'a' && input <= 'z':
+		return rune('A' + c.encode(uint8(input-'a'))), true
+	default:
+		return input, false
+	}
+}
+
+func (c *compiledImpl) EncodeBytes(dst, src []byte) (n int) {
+	for _, b := range src {
+		if n == len(dst) {
+			break
+		}
+
+		switch {
+		case b >= 'A' && b <= 'Z':
+			dst[n] = 'A' + c.encode(b-'A')
+		case b >= 'a' && b <= 'z':
+			dst[n] = 'A' + c.encode(b-'a')
+		default:
+			continue
+		}
+
+		n++
+	}
+
+	return n
+}
+
+func (c *compiledImpl) EncodeSignals(dst, src []parts.Signal) (n int) {
+	for _, s := range src {
+		if n == len(dst) {
+			break
+		}
+
+		if s < 1 || s > 26 {
+			continue
+		}
+
+		dst[n] = parts.Signal(c.encode(uint8(s-1)) + 1)
+		n++
+	}
+
+	return n
+}
+
+// encode steps the rotors and encodes a letter from 0 to 25
+func (c *compiledImpl) encode(x uint8) uint8 {
+	c.step()
+	c.keypresses++
+
+	f := offset(c.window[2], c.ring[2])
+	inner := &c.inner[offset(c.window[0], c.ring[0])*26+offset(c.window[1], c.ring[1])]
+
+	x = c.plug[x]
+	x = c.fast[f][x]
+	x = inner[x]
+	x = c.fastRev[f][x]
+	return c.plug[x]
+}
+
+func (c *compiledImpl) Advance(n uint64) {
+	c.advance(n)
+	c.keypresses += n
+}
+
+func (c *compiledImpl) SeekTo(n uint64) {
+	c.window = c.start
+	c.advance(n)
+	c.keypresses = n
+}
+
+func (c *compiledImpl) Unstep() bool {
+	return c.Backspace(1) == 1
+}
+
+func (c *compiledImpl) Backspace(n uint64) uint64 {
+	if n > c.keypresses {
+		n = c.keypresses
+	}
+
+	c.SeekTo(c.keypresses - n)
+	return n
+}
+
+func offset(window, ring int) int {
+	if window < ring {
+		return window - ring + 26
+	}
+
+	return window - ring
+}
+
+func (c *compiledImpl) EncodeMessage(message string, blockSize uint) string {
+	return c.EncodeMessageWith(message, EncodeOptions{BlockSize: blockSize})
+}
+
+func (c *compiledImpl) EncodeMessageWith(message string, options EncodeOptions) string {
+	return encodeMessageWith(c, message, options)
+}
+
+func (c *compiledImpl) EncodeStrict(message string) (string, error) {
+	return encodeStrict(c, message)
+}
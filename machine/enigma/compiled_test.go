@@ -0,0 +1,110 @@
+package enigma_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/ibraimgm/enigma/machine/parts"
+	"github.com/stretchr/testify/assert"
+)
+
+const benchmarkMessage = "DASOBERKOMMANDODERWEHRMACHTGIBTBEKANNTXAACHENISTGERETTETXDURQGEBUENDELTENEINSATZDERHILFSKRAEFTE"
+
+func TestCompile(t *testing.T) {
+	var tests = []enigma.Settings{
+		{Reflector: "B", Slow: "I", Middle: "II", Fast: "III"},
+		{Reflector: "B", Slow: "III", Middle: "II", Fast: "I", Ring: "AAA", Window: "AEQ"},
+		{Reflector: "C", Slow: "VI", Middle: "VII", Fast: "VIII", Ring: "XKD", Window: "MZL", Plugs: "AZBYCXDW"},
+		{Reflector: "B Dünn", Slow: "IV", Middle: "V", Fast: "VI", Ring: "BUL", Window: "ZYX", Plugs: "QWERTYUIOP"},
+		{Reflector: "C Dünn", Slow: "II", Middle: "VIII", Fast: "V", Ring: "ZZZ", Window: "DMY"},
+	}
+
+	message := strings.Repeat(benchmarkMessage, 50)
+
+	for _, s := range tests {
+		e, err := enigma.WithSettings(s)
+		assert.NoError(t, err)
+
+		c, err := enigma.Compile(e)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{e.Reflector(), e.Slow(), e.Middle(), e.Fast(), e.Ring(), e.Window()},
+			[]string{c.Reflector(), c.Slow(), c.Middle(), c.Fast(), c.Ring(), c.Window()})
+
+		assert.Equal(t, e.EncodeMessageWith("Hello, World!", enigma.EncodeOptions{Passthrough: true}),
+			c.EncodeMessageWith("Hello, World!", enigma.EncodeOptions{Passthrough: true}))
+		assert.Equal(t, e.EncodeMessage(message, 5), c.EncodeMessage(message, 5))
+		assert.Equal(t, e.Window(), c.Window())
+	}
+}
+
+func TestCompileIsIndependent(t *testing.T) {
+	e, _ := enigma.WithConfig("SKY", "RIM")
+	c, err := enigma.Compile(e)
+	assert.NoError(t, err)
+
+	c.EncodeMessage("ENIGMA", 0)
+	assert.Equal(t, "RIM", e.Window())
+	assert.Equal(t, "RJS", c.Window())
+
+	c2, err := enigma.Compile(c)
+	assert.NoError(t, err)
+	c2.EncodeMessage("ENIGMA", 0)
+	assert.Equal(t, "RJS", c.Window())
+	assert.Equal(t, "RJY", c2.Window())
+
+	s := enigma.NewSynchronized(c2)
+	c3, err := enigma.Compile(s)
+	assert.NoError(t, err)
+	c3.EncodeMessage("ENIGMA", 0)
+	assert.Equal(t, "RJY", s.Window())
+	assert.Equal(t, "RJE", c3.Window())
+
+	assert.NoError(t, c.Configure("SKY", "RIM"))
+	assert.Equal(t, "LZCKRSK", c.EncodeMessage(e.EncodeMessage("LZC KR SK", 0), 0))
+
+	assert.Error(t, c.SetWindow("ZZ"))
+	assert.Error(t, c.SetRing("abc"))
+}
+
+// plainRotor is a rotor that does not report its wiring
+type plainRotor struct {
+	parts.Rotor
+}
+
+func TestCompileError(t *testing.T) {
+	rotor := func(id string) parts.Rotor {
+		r, _ := parts.GetRotor(id)
+		return r
+	}
+
+	e := enigma.Assemble(parts.DefaultKeyboard, parts.NoPlugboard, rotor("I"), plainRotor{rotor("II")}, rotor("III"),
+		parts.Reflectors["B"], parts.DefaultLightboard)
+	_, err := enigma.Compile(e)
+	assert.EqualError(t, err, "rotor 'II' does not report its wiring")
+
+	e = enigma.Assemble(parts.DefaultKeyboard, parts.NoPlugboard, rotor("I"), rotor("II"), rotor("III"),
+		parts.Reflectors["B"], nil)
+	_, err = enigma.Compile(e)
+	assert.EqualError(t, err, "only machines with the default keyboard and lightboard can be compiled")
+
+	_, err = enigma.Compile(nil)
+	assert.EqualError(t, err, "only machines built by the enigma package can be compiled")
+}
+
+func benchmarkEncode(b *testing.B, e enigma.Enigma) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		e.Encode(rune(benchmarkMessage[i%len(benchmarkMessage)]))
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	benchmarkEncode(b, enigma.WithDefaults())
+}
+
+func BenchmarkCompiledEncode(b *testing.B) {
+	c, _ := enigma.Compile(enigma.WithDefaults())
+	benchmarkEncode(b, c)
+}
@@ -2,13 +2,17 @@
 package enigma
 
 import (
-	"errors"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/ibraimgm/enigma/machine/parts"
 )
 
 // Enigma is a interface describing a generic 3-rotor enigma machine.
 // Once built, you cannot change the machine parts, but can configure the window settings and the ring settings
+//
+// Every keypress moves the rotors, so a machine is not safe for concurrent use. To share a machine between goroutines,
+// wrap it with NewSynchronized, or use a Pool to give each goroutine its own machine.
 type Enigma interface {
 	Reflector() string
 	Slow() string
@@ -21,6 +25,47 @@ type Enigma interface {
 	Configure(ringSetting, windowSetting string) error
 	Encode(input rune) (rune, bool)
 	EncodeMessage(message string, blockSize uint) string
+	EncodeMessageWith(message string, options EncodeOptions) string
+	// EncodeStrict encodes the message without blocks, like EncodeMessage, but every character must be accepted by
+	// the keyboard. Otherwise, it returns an InvalidRuneError with the first character rejected, and the rotors are
+	// turned back to their position before the call.
+	EncodeStrict(message string) (string, error)
+	// EncodeBytes encodes the letters of src into dst, discarding every other byte, and returns the number of bytes
+	// written. It stops when dst is full, so a dst as long as src is always enough. Encoding in place (with dst and
+	// src being the same slice) is allowed, and no memory is allocated.
+	EncodeBytes(dst, src []byte) (n int)
+	// EncodeSignals is like EncodeBytes, but works with signals from 1 to 26, without the keyboard and the
+	// lightboard. Signals outside of this range are discarded.
+	EncodeSignals(dst, src []parts.Signal) (n int)
+	// Advance moves the rotors as if n letters were encoded, without encoding them. The double step of the middle
+	// rotor is honored, and the cost does not depend on n. It only moves forward: to move back, use SeekTo (counted
+	// from the last window setting) or Backspace.
+	Advance(n uint64)
+	// SeekTo moves the rotors to the position of the n-th letter of the message, as if n letters were encoded from
+	// the last window setting. Unlike Advance, it can also move the rotors back.
+	SeekTo(n uint64)
+	// Unstep turns the rotors back to their position before the last keypress, as an operator correcting a typo.
+	// Since the double step of the middle rotor cannot be reversed by looking at the rotors alone, the machine counts
+	// the keypresses since the last window setting. It returns false when there is no keypress to undo.
+	Unstep() bool
+	// Backspace is like Unstep, but undoes the last n keypresses at once. It returns the number of keypresses undone.
+	Backspace(n uint64) uint64
+}
+
+// EncodeOptions controls how a message is encoded by EncodeMessageWith.
+type EncodeOptions struct {
+	// BlockSize is the number of encoded letters in each block. Zero means no blocks.
+	// It is ignored when Passthrough is set.
+	BlockSize uint
+	// Backspace makes the backspace and delete characters ('\b' and '\x7f') erase the last character of the
+	// message, as an operator correcting a typo: the character is removed from the output and, if it is a letter,
+	// the rotors are turned back.
+	Backspace bool
+	// Passthrough keeps the characters rejected by the keyboard in their original positions, instead of discarding
+	// them. These characters do not move the rotors.
+	Passthrough bool
+	// PreserveCase makes lowercase letters to be encoded as lowercase letters.
+	PreserveCase bool
 }
 
 type enigmaImpl struct {
@@ -31,6 +76,9 @@ type enigmaImpl struct {
 	fast       parts.Rotor
 	reflector  parts.Reflector
 	lightboard parts.Lightboard
+	// start is the last window setting, and keypresses is the number of keypresses since it was set
+	start      string
+	keypresses uint64
 }
 
 // WithDefaults builds a new enigma machine, with the rotors III, II and I (from slow to fast), using the "B" reflector
@@ -52,33 +100,7 @@ func WithDefaults() Enigma {
 
 // WithRotors build a new enigma machine, with the specified rotors and reflector.
 func WithRotors(slow, middle, fast, reflector string) (Enigma, error) {
-	var r1, r2, r3 parts.Rotor
-	var err error
-
-	if r1, err = parts.GetRotor(slow); err != nil {
-		return nil, err
-	}
-	if r2, err = parts.GetRotor(middle); err != nil {
-		return nil, err
-	}
-	if r3, err = parts.GetRotor(fast); err != nil {
-		return nil, err
-	}
-
-	ref, ok := parts.Reflectors[reflector]
-	if !ok {
-		return nil, errors.New("unknown reflector: '" + reflector + "'")
-	}
-
-	return Assemble(
-		parts.DefaultKeyboard,
-		parts.NoPlugboard,
-		r1,
-		r2,
-		r3,
-		ref,
-		parts.DefaultLightboard,
-	), nil
+	return WithSettings(Settings{Reflector: reflector, Slow: slow, Middle: middle, Fast: fast})
 }
 
 // WithConfig builds a new enigma machine, with default rotors and reflector, using the specified settings.
@@ -92,7 +114,7 @@ func WithConfig(ringSetting, windowSetting string) (Enigma, error) {
 // Assemble builds a new enigma machine, with default config and all parts specified.
 // This is the only way to create a machine with a different keyboard, lightboard or plugboard
 func Assemble(keyboard parts.Keyboard, plugboard parts.Plugboard, slow, middle, fast parts.Rotor, reflector parts.Reflector, lightboard parts.Lightboard) Enigma {
-	enigma := &enigmaImpl{keyboard, plugboard, slow, middle, fast, reflector, lightboard}
+	enigma := &enigmaImpl{keyboard, plugboard, slow, middle, fast, reflector, lightboard, "", 0}
 	enigma.SetWindow("AAA")
 	enigma.SetRing("AAA")
 
@@ -124,30 +146,56 @@ func (e *enigmaImpl) Window() string {
 }
 
 func (e *enigmaImpl) SetWindow(settings string) error {
-	var runes []rune
-
-	if settings == "" {
-		runes = []rune{'A', 'A', 'A'}
-	} else {
-		runes = []rune(settings)
-
-		if len(runes) != 3 {
-			return errors.New("window settings should be 3 characters long (ex: AAA)")
-		}
-
-		for _, c := range runes {
-			if c < 'A' || c > 'Z' {
-				return errors.New("window settings should be specified using only uppercase letters from 'A' to 'Z'")
-			}
-		}
+	runes, err := parseLetters(settings, "window")
+	if err != nil {
+		return err
 	}
 
 	e.slow.SetWindow(runes[0])
 	e.middle.SetWindow(runes[1])
 	e.fast.SetWindow(runes[2])
+	e.start = string(runes)
+	e.keypresses = 0
 	return nil
 }
 
+func (e *enigmaImpl) rotors() []parts.Rotor {
+	return []parts.Rotor{e.slow, e.middle, e.fast}
+}
+
+func (e *enigmaImpl) Advance(n uint64) {
+	s := e.stackOf()
+	s.advance(n)
+
+	for i, r := range e.rotors() {
+		r.SetWindow(rune('A' + s.window[i]))
+	}
+
+	e.keypresses += n
+}
+
+func (e *enigmaImpl) SeekTo(n uint64) {
+	for i, r := range e.rotors() {
+		r.SetWindow(rune(e.start[i]))
+	}
+
+	e.keypresses = 0
+	e.Advance(n)
+}
+
+func (e *enigmaImpl) Unstep() bool {
+	return e.Backspace(1) == 1
+}
+
+func (e *enigmaImpl) Backspace(n uint64) uint64 {
+	if n > e.keypresses {
+		n = e.keypresses
+	}
+
+	e.SeekTo(e.keypresses - n)
+	return n
+}
+
 func (e *enigmaImpl) Ring() string {
 	return string([]rune{
 		e.slow.Ring(),
@@ -157,28 +205,37 @@ func (e *enigmaImpl) Ring() string {
 }
 
 func (e *enigmaImpl) SetRing(settings string) error {
-	var runes []rune
+	runes, err := parseLetters(settings, "ring")
+	if err != nil {
+		return err
+	}
+
+	e.slow.SetRing(runes[0])
+	e.middle.SetRing(runes[1])
+	e.fast.SetRing(runes[2])
+	return nil
+}
 
+// parseLetters validates the window or ring settings (named by kind), returning the letters of the slow, middle and
+// fast rotors. An empty string means 'AAA'.
+func parseLetters(settings, kind string) ([]rune, error) {
 	if settings == "" {
-		runes = []rune{'A', 'A', 'A'}
-	} else {
-		runes = []rune(settings)
+		return []rune{'A', 'A', 'A'}, nil
+	}
 
-		if len(runes) != 3 {
-			return errors.New("ring settings should be 3 characters long (ex: AAA)")
-		}
+	runes := []rune(settings)
 
-		for _, c := range runes {
-			if c < 'A' || c > 'Z' {
-				return errors.New("ring settings should be specified using only uppercase letters from 'A' to 'Z'")
-			}
+	if len(runes) != 3 {
+		return nil, settingError(kind + " settings should be 3 characters long (ex: AAA)")
+	}
+
+	for _, c := range runes {
+		if c < 'A' || c > 'Z' {
+			return nil, settingError(kind + " settings should be specified using only uppercase letters from 'A' to 'Z'")
 		}
 	}
 
-	e.slow.SetRing(runes[0])
-	e.middle.SetRing(runes[1])
-	e.fast.SetRing(runes[2])
-	return nil
+	return runes, nil
 }
 
 func (e *enigmaImpl) Configure(ringSetting, windowSetting string) error {
@@ -196,6 +253,13 @@ func (e *enigmaImpl) Encode(input rune) (rune, bool) {
 		return input, false
 	}
 
+	return e.lightboard.Light(e.encodeSignal(signal)), true
+}
+
+// encodeSignal steps the rotors and sends the signal through the plugboard, rotors and reflector
+func (e *enigmaImpl) encodeSignal(signal parts.Signal) parts.Signal {
+	e.keypresses++
+
 	// stepping
 	if e.fast.IsNotched() {
 		if e.middle.IsNotched() {
@@ -219,28 +283,150 @@ func (e *enigmaImpl) Encode(input rune) (rune, bool) {
 	signal = e.slow.Reverse(signal)
 	signal = e.middle.Reverse(signal)
 	signal = e.fast.Reverse(signal)
-	signal = e.plugboard.Translate(signal)
-	return e.lightboard.Light(signal), true
+	return e.plugboard.Translate(signal)
+}
+
+func (e *enigmaImpl) EncodeBytes(dst, src []byte) (n int) {
+	for _, b := range src {
+		if n == len(dst) {
+			break
+		}
+
+		encoded, ok := e.Encode(rune(b))
+		if !ok {
+			continue
+		}
+
+		dst[n] = byte(encoded)
+		n++
+	}
+
+	return n
+}
+
+func (e *enigmaImpl) EncodeSignals(dst, src []parts.Signal) (n int) {
+	for _, s := range src {
+		if n == len(dst) {
+			break
+		}
+
+		if s < 1 || s > 26 {
+			continue
+		}
+
+		dst[n] = e.encodeSignal(s)
+		n++
+	}
+
+	return n
 }
 
 func (e *enigmaImpl) EncodeMessage(message string, blockSize uint) string {
+	return e.EncodeMessageWith(message, EncodeOptions{BlockSize: blockSize})
+}
+
+func (e *enigmaImpl) EncodeMessageWith(message string, options EncodeOptions) string {
+	return encodeMessageWith(e, message, options)
+}
+
+func (e *enigmaImpl) EncodeStrict(message string) (string, error) {
+	return encodeStrict(e, message)
+}
+
+// encodeStrict encodes the message with any implementation of Encode and Backspace
+func encodeStrict(e Enigma, message string) (string, error) {
+	buf := make([]byte, 0, len(message))
+	var keypresses uint64
+
+	for i, c := range []rune(message) {
+		encoded, ok := e.Encode(c)
+		if !ok {
+			e.Backspace(keypresses)
+			return "", &InvalidRuneError{Position: i, Rune: c}
+		}
+
+		buf = appendRune(buf, encoded)
+		keypresses++
+	}
+
+	return string(buf), nil
+}
+
+// encodeMessageWith encodes the message with any implementation of Encode
+func encodeMessageWith(e Enigma, message string, options EncodeOptions) string {
+	// the state before each character, to erase it with a backspace
+	type mark struct {
+		size     int
+		currSize uint
+		isLetter bool
+	}
+
+	var marks []mark
 	var currSize uint
-	var currMsg string
+	blockSize := options.BlockSize
+	buf := make([]byte, 0, len(message)+len(message)/5)
+
+	if options.Passthrough {
+		blockSize = 0
+	}
 
 	for _, c := range message {
+		if options.Backspace && (c == '\b' || c == '\x7f') {
+			if len(marks) > 0 {
+				m := marks[len(marks)-1]
+				marks = marks[:len(marks)-1]
+				buf, currSize = buf[:m.size], m.currSize
+
+				if m.isLetter {
+					e.Unstep()
+				}
+			}
+
+			continue
+		}
+
+		m := mark{len(buf), currSize, false}
+
 		encoded, ok := e.Encode(c)
 		if !ok {
+			if options.Passthrough {
+				buf = appendRune(buf, c)
+
+				if options.Backspace {
+					marks = append(marks, m)
+				}
+			}
+
 			continue
 		}
 
+		if options.PreserveCase && unicode.IsLower(c) {
+			encoded = unicode.ToLower(encoded)
+		}
+
 		if blockSize > 0 && currSize == blockSize {
-			currMsg = currMsg + " "
+			buf = append(buf, ' ')
 			currSize = 0
 		}
 
-		currMsg = currMsg + string(encoded)
+		buf = appendRune(buf, encoded)
 		currSize++
+
+		if options.Backspace {
+			m.isLetter = true
+			marks = append(marks, m)
+		}
+	}
+
+	return string(buf)
+}
+
+func appendRune(buf []byte, r rune) []byte {
+	if r < utf8.RuneSelf {
+		return append(buf, byte(r))
 	}
 
-	return currMsg
+	var tmp [utf8.UTFMax]byte
+	n := utf8.EncodeRune(tmp[:], r)
+	return append(buf, tmp[:n]...)
 }
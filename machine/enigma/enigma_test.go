@@ -158,6 +158,36 @@ func TestEncodeMessage(t *testing.T) {
 	}
 }
 
+func TestEncodeMessageWith(t *testing.T) {
+	var tests = []struct {
+		original string
+		options  enigma.EncodeOptions
+		expected string
+	}{
+		{"Enigma machine", enigma.EncodeOptions{BlockSize: 5}, "VWJBF IGXKX EOS"},
+		{"Enigma machine", enigma.EncodeOptions{BlockSize: 5, PreserveCase: true}, "Vwjbf igxkx eos"},
+		{"Enigma machine", enigma.EncodeOptions{BlockSize: 5, Passthrough: true}, "VWJBFI GXKXEOS"},
+		{"Enigma, 2 machines!", enigma.EncodeOptions{Passthrough: true, PreserveCase: true}, "Vwjbfi, 2 gxkxeosz!"},
+	}
+
+	e := enigma.WithDefaults()
+
+	for _, test := range tests {
+		e.Configure("", "")
+		actual := e.EncodeMessageWith(test.original, test.options)
+		assert.Equal(t, test.expected, actual)
+	}
+}
+
+func TestEncodeMessageWithDecode(t *testing.T) {
+	e, _ := enigma.WithConfig("SKY", "RIM")
+	options := enigma.EncodeOptions{Passthrough: true, PreserveCase: true}
+	s := e.EncodeMessageWith("Hello, World!", options)
+
+	e.Configure("SKY", "RIM")
+	assert.Equal(t, "Hello, World!", e.EncodeMessageWith(s, options))
+}
+
 func TestEncodeDecode(t *testing.T) {
 	e, _ := enigma.WithConfig("SKY", "RIM")
 	s := e.EncodeMessage("LZC KR SK", 0)
@@ -0,0 +1,46 @@
+package enigma
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ibraimgm/enigma/machine/parts"
+)
+
+var (
+	// ErrInvalidRune is wrapped by the errors of characters that cannot be encoded (see InvalidRuneError).
+	ErrInvalidRune = errors.New("invalid rune")
+	// ErrInvalidSetting is wrapped by the errors of invalid window, ring, reflector or plug settings, and of settings
+	// strings that cannot be parsed.
+	ErrInvalidSetting = errors.New("invalid setting")
+	// ErrUnknownRotor is wrapped by the errors of unknown rotor IDs. It is the same as parts.ErrUnknownRotor.
+	ErrUnknownRotor = parts.ErrUnknownRotor
+)
+
+// InvalidRuneError is the error of a character that cannot be encoded. It wraps ErrInvalidRune.
+type InvalidRuneError struct {
+	// Position is the position of the character, from 0. It is counted in characters for strings, and in bytes for
+	// a Stream.
+	Position int
+	// Rune is the character.
+	Rune rune
+}
+
+func (e *InvalidRuneError) Error() string {
+	return fmt.Sprintf("the character %q at %d cannot be encoded", e.Rune, e.Position)
+}
+
+func (e *InvalidRuneError) Unwrap() error {
+	return ErrInvalidRune
+}
+
+// settingError is an error that wraps ErrInvalidSetting, keeping its own message
+type settingError string
+
+func (e settingError) Error() string {
+	return string(e)
+}
+
+func (e settingError) Unwrap() error {
+	return ErrInvalidSetting
+}
@@ -0,0 +1,93 @@
+package enigma_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeStrict(t *testing.T) {
+	for _, m := range compiledAndDefault(t, steppingSettings[2]) {
+		machines := []enigma.Enigma{m, enigma.NewSynchronized(m)}
+
+		for _, e := range machines {
+			assert.NoError(t, e.SetWindow(steppingSettings[2].Window))
+			expected := e.EncodeMessage("ENIGMAmachine", 0)
+			assert.NoError(t, e.SetWindow(steppingSettings[2].Window))
+
+			encoded, err := e.EncodeStrict("ENIGMAmachine")
+			assert.NoError(t, err)
+			assert.Equal(t, expected, encoded)
+
+			// the rotors are turned back on errors
+			window := e.Window()
+			encoded, err = e.EncodeStrict("Enigma machine")
+			assert.Equal(t, "", encoded)
+			assert.EqualError(t, err, "the character ' ' at 6 cannot be encoded")
+			assert.Equal(t, window, e.Window())
+
+			_, err = e.EncodeStrict("Rotorwalzeüber")
+			assert.EqualError(t, err, "the character 'ü' at 10 cannot be encoded")
+			assert.True(t, errors.Is(err, enigma.ErrInvalidRune))
+
+			var runeErr *enigma.InvalidRuneError
+			assert.True(t, errors.As(err, &runeErr))
+			assert.Equal(t, &enigma.InvalidRuneError{Position: 10, Rune: 'ü'}, runeErr)
+			assert.Equal(t, window, e.Window())
+		}
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	e := enigma.WithDefaults()
+	valid := enigma.Settings{Reflector: "B", Slow: "III", Middle: "II", Fast: "I"}
+
+	settingErrors := []error{
+		e.SetWindow("AB"),
+		e.SetRing("abc"),
+		e.Configure("AAA", "A1A"),
+	}
+
+	for _, change := range []func(s *enigma.Settings){
+		func(s *enigma.Settings) { s.Reflector = "X" },
+		func(s *enigma.Settings) { s.Plugs = "ABA" },
+		func(s *enigma.Settings) { s.Plugs = "ABAC" },
+		func(s *enigma.Settings) { s.Window = "ZZZZ" },
+	} {
+		s := valid
+		change(&s)
+		_, err := enigma.WithSettings(s)
+		settingErrors = append(settingErrors, err)
+	}
+
+	for _, settings := range []string{"B III II", "B III II I 01 99 01", "reflector=B speed=10", "B III II I AAA AAA A"} {
+		_, err := enigma.ParseSettingsString(settings, enigma.AutoNotation)
+		settingErrors = append(settingErrors, err)
+	}
+
+	_, err := enigma.ParseSettingsString("B III II I", enigma.Notation(99))
+	settingErrors = append(settingErrors, err)
+
+	_, err = enigma.RandomSettings(nil, enigma.Model{Rotors: []string{"I", "II"}, Reflectors: []string{"B"}}, enigma.DefaultConstraints)
+	settingErrors = append(settingErrors, err)
+
+	_, err = enigma.RandomSettings(nil, enigma.Models["I"], enigma.Constraints{Plugs: 14})
+	settingErrors = append(settingErrors, err)
+
+	for _, err := range settingErrors {
+		assert.True(t, errors.Is(err, enigma.ErrInvalidSetting), "%v", err)
+		assert.False(t, errors.Is(err, enigma.ErrUnknownRotor), "%v", err)
+	}
+
+	s := valid
+	s.Middle = "IX"
+	_, err = enigma.WithSettings(s)
+	assert.EqualError(t, err, "unrecognized rotor ID: 'IX'")
+	assert.True(t, errors.Is(err, enigma.ErrUnknownRotor))
+	assert.False(t, errors.Is(err, enigma.ErrInvalidSetting))
+
+	_, err = enigma.NewStream(e, enigma.RejectNonLetters).Transform(make([]byte, 3), []byte("AB1"))
+	assert.True(t, errors.Is(err, enigma.ErrInvalidRune))
+}
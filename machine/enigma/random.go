@@ -0,0 +1,167 @@
+package enigma
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+)
+
+// Model describes the rotors and reflectors available for a historical enigma model.
+type Model struct {
+	Name       string
+	Rotors     []string
+	Reflectors []string
+}
+
+// Models is a map with the historical enigma models. The valid keys are "I" (the Heer and Luftwaffe Enigma I,
+// with rotors I to V) and "M3" (the Kriegsmarine M3, with rotors I to VIII).
+var Models = map[string]Model{
+	"I": {
+		Name:       "I",
+		Rotors:     []string{"I", "II", "III", "IV", "V"},
+		Reflectors: []string{"B", "C"},
+	},
+	"M3": {
+		Name:       "M3",
+		Rotors:     []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII"},
+		Reflectors: []string{"B", "C"},
+	},
+}
+
+// Orders returns every rotor order (without repetition) that can be made with the rotors of the model.
+func (m Model) Orders() [][3]string {
+	return RotorOrders(m.Rotors)
+}
+
+// RotorOrders returns every rotor order (slow, middle and fast, without repetition) that can be made with the
+// specified rotors.
+func RotorOrders(rotors []string) [][3]string {
+	orders := [][3]string{}
+
+	for _, slow := range rotors {
+		for _, middle := range rotors {
+			for _, fast := range rotors {
+				if slow != middle && slow != fast && middle != fast {
+					orders = append(orders, [3]string{slow, middle, fast})
+				}
+			}
+		}
+	}
+
+	return orders
+}
+
+// Constraints controls the settings generated by RandomSettings.
+// The rotors are always distinct, as in the historical key sheets.
+type Constraints struct {
+	// Plugs is the number of plug pairs, from 0 to 13.
+	Plugs int
+	// NoAdjacentPlugs avoids plugs connecting letters that are adjacent in the alphabet (like 'AB' or 'ZA').
+	NoAdjacentPlugs bool
+}
+
+// DefaultConstraints are the constraints of the most common historical key sheets: 10 plug pairs.
+var DefaultConstraints = Constraints{Plugs: 10}
+
+// RandomSettings builds a random key for the specified model.
+// The random numbers are read from rng; if nil, crypto/rand is used. For reproducible keys, use a seeded
+// math/rand source, like rand.New(rand.NewSource(42)).
+func RandomSettings(rng io.Reader, model Model, constraints Constraints) (Settings, error) {
+	if rng == nil {
+		rng = rand.Reader
+	}
+
+	if len(model.Rotors) < 3 || len(model.Reflectors) == 0 {
+		return Settings{}, settingError("model should have at least 3 rotors and 1 reflector")
+	}
+
+	if constraints.Plugs < 0 || constraints.Plugs > 13 {
+		return Settings{}, settingError("the number of plugs should be between 0 and 13")
+	}
+
+	r := &randomReader{rng: rng}
+	var s Settings
+
+	s.Reflector = model.Reflectors[r.intn(len(model.Reflectors))]
+
+	rotors := append([]string(nil), model.Rotors...)
+	r.shuffle(rotors)
+	s.Slow, s.Middle, s.Fast = rotors[0], rotors[1], rotors[2]
+
+	s.Ring = r.letters(3)
+	s.Window = r.letters(3)
+	s.Plugs = r.plugs(constraints)
+
+	if r.err != nil {
+		return Settings{}, r.err
+	}
+
+	return s, nil
+}
+
+// randomReader keeps the first error, so the caller can check it only once
+type randomReader struct {
+	rng io.Reader
+	err error
+}
+
+// intn returns an uniform random number in [0, n)
+func (r *randomReader) intn(n int) int {
+	var buf [4]byte
+	limit := (1 << 32) - (1<<32)%uint64(n)
+
+	for r.err == nil {
+		if _, r.err = io.ReadFull(r.rng, buf[:]); r.err != nil {
+			break
+		}
+
+		if v := uint64(binary.BigEndian.Uint32(buf[:])); v < limit {
+			return int(v % uint64(n))
+		}
+	}
+
+	return 0
+}
+
+func (r *randomReader) shuffle(values []string) {
+	for i := len(values) - 1; i > 0; i-- {
+		j := r.intn(i + 1)
+		values[i], values[j] = values[j], values[i]
+	}
+}
+
+func (r *randomReader) letters(n int) string {
+	runes := make([]rune, n)
+
+	for i := range runes {
+		runes[i] = rune('A' + r.intn(26))
+	}
+
+	return string(runes)
+}
+
+func (r *randomReader) plugs(constraints Constraints) string {
+	for r.err == nil {
+		letters := []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M",
+			"N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y", "Z"}
+		r.shuffle(letters)
+
+		plugs := ""
+		ok := true
+		for i := 0; i < constraints.Plugs*2; i += 2 {
+			a, b := letters[i][0], letters[i+1][0]
+			if constraints.NoAdjacentPlugs && (a-b == 1 || b-a == 1 || a-b == 25 || b-a == 25) {
+				ok = false
+				break
+			}
+
+			plugs += letters[i] + letters[i+1]
+		}
+
+		if ok {
+			return plugs
+		}
+	}
+
+	return ""
+}
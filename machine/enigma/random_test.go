@@ -0,0 +1,80 @@
+package enigma_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomSettings(t *testing.T) {
+	for _, model := range enigma.Models {
+		s, err := enigma.RandomSettings(nil, model, enigma.DefaultConstraints)
+		assert.NoError(t, err)
+
+		assert.Contains(t, model.Reflectors, s.Reflector)
+		assert.Contains(t, model.Rotors, s.Slow)
+		assert.Contains(t, model.Rotors, s.Middle)
+		assert.Contains(t, model.Rotors, s.Fast)
+		assert.NotEqual(t, s.Slow, s.Middle)
+		assert.NotEqual(t, s.Slow, s.Fast)
+		assert.NotEqual(t, s.Middle, s.Fast)
+		assert.Regexp(t, "^[A-Z]{3}$", s.Ring)
+		assert.Regexp(t, "^[A-Z]{3}$", s.Window)
+		assert.Len(t, s.Plugs, 20)
+
+		_, err = enigma.WithSettings(s)
+		assert.NoError(t, err)
+	}
+}
+
+func TestRandomSettingsSeed(t *testing.T) {
+	s1, err := enigma.RandomSettings(rand.New(rand.NewSource(42)), enigma.Models["M3"], enigma.DefaultConstraints)
+	assert.NoError(t, err)
+
+	s2, err := enigma.RandomSettings(rand.New(rand.NewSource(42)), enigma.Models["M3"], enigma.DefaultConstraints)
+	assert.NoError(t, err)
+	assert.Equal(t, s1, s2)
+
+	s3, err := enigma.RandomSettings(rand.New(rand.NewSource(43)), enigma.Models["M3"], enigma.DefaultConstraints)
+	assert.NoError(t, err)
+	assert.NotEqual(t, s1, s3)
+}
+
+func TestRandomSettingsPlugs(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for plugs := 0; plugs <= 13; plugs++ {
+		s, err := enigma.RandomSettings(rng, enigma.Models["I"], enigma.Constraints{Plugs: plugs, NoAdjacentPlugs: true})
+		assert.NoError(t, err)
+		assert.Len(t, s.Plugs, plugs*2)
+
+		for i := 0; i < len(s.Plugs); i += 2 {
+			diff := int(s.Plugs[i]) - int(s.Plugs[i+1])
+			assert.NotContains(t, []int{-25, -1, 1, 25}, diff, "adjacent plug: %s", s.Plugs[i:i+2])
+		}
+	}
+}
+
+func TestRotorOrders(t *testing.T) {
+	orders := enigma.RotorOrders([]string{"I", "II", "III"})
+	assert.Len(t, orders, 6)
+	assert.Contains(t, orders, [3]string{"III", "I", "II"})
+	assert.NotContains(t, orders, [3]string{"I", "I", "II"})
+
+	assert.Len(t, enigma.Models["I"].Orders(), 60)
+	assert.Len(t, enigma.Models["M3"].Orders(), 336)
+}
+
+func TestRandomSettingsError(t *testing.T) {
+	_, err := enigma.RandomSettings(nil, enigma.Model{Rotors: []string{"I", "II"}, Reflectors: []string{"B"}}, enigma.DefaultConstraints)
+	assert.EqualError(t, err, "model should have at least 3 rotors and 1 reflector")
+
+	_, err = enigma.RandomSettings(nil, enigma.Models["I"], enigma.Constraints{Plugs: 14})
+	assert.EqualError(t, err, "the number of plugs should be between 0 and 13")
+
+	_, err = enigma.RandomSettings(bytes.NewReader([]byte{1, 2, 3}), enigma.Models["I"], enigma.DefaultConstraints)
+	assert.EqualError(t, err, "unexpected EOF")
+}
@@ -0,0 +1,349 @@
+package enigma
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ibraimgm/enigma/machine/parts"
+)
+
+// Settings holds the full configuration of an enigma machine built with the default parts.
+// Plugs is a list of letter pairs, in the same format accepted by parts.CreatePlugboard (ex: "ABCD").
+type Settings struct {
+	Reflector string
+	Slow      string
+	Middle    string
+	Fast      string
+	Ring      string
+	Window    string
+	Plugs     string
+}
+
+// Notation is the format of a settings string.
+type Notation int
+
+const (
+	// AutoNotation detects the notation when parsing a settings string. It cannot be used for formatting.
+	AutoNotation Notation = iota
+	// CompactNotation lists the reflector, the rotors, the ring settings, the window settings and the plugs,
+	// separated by spaces. Ex: "B III II I AAA AAA AB CD".
+	CompactNotation
+	// KeySheetNotation is the notation of the historical key sheets, with the ring settings written as numbers.
+	// The window settings are optional. Ex: "B III II I 01 01 01 AAA AB CD".
+	KeySheetNotation
+	// KeyValueNotation names each setting, just like the command-line flags.
+	// Ex: "reflector=B rotors=III,II,I ring=AAA window=AAA plugs=AB,CD".
+	KeyValueNotation
+)
+
+// WithSettings builds a new enigma machine, with the rotors, reflector, plugboard and configuration specified.
+func WithSettings(settings Settings) (Enigma, error) {
+	var r1, r2, r3 parts.Rotor
+	var err error
+
+	if r1, err = parts.GetRotor(settings.Slow); err != nil {
+		return nil, err
+	}
+	if r2, err = parts.GetRotor(settings.Middle); err != nil {
+		return nil, err
+	}
+	if r3, err = parts.GetRotor(settings.Fast); err != nil {
+		return nil, err
+	}
+
+	ref, ok := parts.Reflectors[settings.Reflector]
+	if !ok {
+		return nil, settingError("unknown reflector: '" + settings.Reflector + "'")
+	}
+
+	if err = validatePlugs(settings.Plugs); err != nil {
+		return nil, err
+	}
+
+	plugboard := parts.NoPlugboard
+	if settings.Plugs != "" {
+		plugboard = parts.CreatePlugboard(settings.Plugs)
+	}
+
+	e := Assemble(parts.DefaultKeyboard, plugboard, r1, r2, r3, ref, parts.DefaultLightboard)
+	if err = e.Configure(settings.Ring, settings.Window); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// String returns the settings using the compact notation.
+func (s Settings) String() string {
+	return FormatSettingsString(s, CompactNotation)
+}
+
+// ParseSettingsString reads the settings from a string written in the specified notation.
+// When notation is AutoNotation, the notation is detected from the string contents.
+// Only the syntax is checked; invalid rotors or reflectors are reported when the machine is built.
+func ParseSettingsString(settings string, notation Notation) (Settings, error) {
+	if notation == AutoNotation {
+		notation = detectNotation(settings)
+	}
+
+	switch notation {
+	case CompactNotation, KeySheetNotation:
+		return parseTokens(settings, notation)
+	case KeyValueNotation:
+		return parseKeyValue(settings)
+	default:
+		return Settings{}, settingError(fmt.Sprintf("unknown settings notation: %d", notation))
+	}
+}
+
+// FormatSettingsString writes the settings using the specified notation.
+// AutoNotation is the same as CompactNotation.
+func FormatSettingsString(settings Settings, notation Notation) string {
+	plugs := plugPairs(settings.Plugs)
+	ring := orDefault(settings.Ring)
+	window := orDefault(settings.Window)
+
+	switch notation {
+	case KeyValueNotation:
+		s := fmt.Sprintf("reflector=%s rotors=%s,%s,%s ring=%s window=%s", settings.Reflector,
+			settings.Slow, settings.Middle, settings.Fast, ring, window)
+		if len(plugs) > 0 {
+			s += " plugs=" + strings.Join(plugs, ",")
+		}
+		return s
+	case KeySheetNotation:
+		numbers := make([]string, 0, 3)
+		for _, c := range ring {
+			numbers = append(numbers, fmt.Sprintf("%02d", c-'A'+1))
+		}
+		fields := []string{settings.Reflector, settings.Slow, settings.Middle, settings.Fast, strings.Join(numbers, " "), window}
+		return strings.Join(append(fields, plugs...), " ")
+	default:
+		fields := []string{settings.Reflector, settings.Slow, settings.Middle, settings.Fast, ring, window}
+		return strings.Join(append(fields, plugs...), " ")
+	}
+}
+
+func detectNotation(settings string) Notation {
+	if strings.Contains(settings, "=") {
+		return KeyValueNotation
+	}
+
+	if _, tokens := parseReflector(strings.Fields(settings)); len(tokens) > 3 {
+		if _, err := strconv.Atoi(tokens[3]); err == nil {
+			return KeySheetNotation
+		}
+	}
+
+	return CompactNotation
+}
+
+// parseReflector finds the reflector name in the start of tokens, returning the name and the remaining tokens.
+// Reflector names with spaces (like "B Dünn") and a "UKW" prefix are recognized.
+func parseReflector(tokens []string) (string, []string) {
+	if len(tokens) > 0 && strings.EqualFold(tokens[0], "UKW") {
+		tokens = tokens[1:]
+	}
+
+	if len(tokens) == 0 {
+		return "", tokens
+	}
+
+	if len(tokens) > 1 {
+		for name := range parts.Reflectors {
+			if strings.EqualFold(name, tokens[0]+" "+tokens[1]) {
+				return name, tokens[2:]
+			}
+		}
+	}
+
+	for name := range parts.Reflectors {
+		if strings.EqualFold(name, tokens[0]) {
+			return name, tokens[1:]
+		}
+	}
+
+	return tokens[0], tokens[1:]
+}
+
+func parseTokens(settings string, notation Notation) (Settings, error) {
+	var s Settings
+	var tokens []string
+
+	s.Reflector, tokens = parseReflector(strings.Fields(settings))
+	if len(tokens) < 3 {
+		return Settings{}, settingError("settings should specify the reflector and 3 rotors (ex: B III II I)")
+	}
+
+	s.Slow, s.Middle, s.Fast = strings.ToUpper(tokens[0]), strings.ToUpper(tokens[1]), strings.ToUpper(tokens[2])
+	tokens = tokens[3:]
+
+	if notation == KeySheetNotation {
+		if len(tokens) < 3 {
+			return Settings{}, settingError("key sheet settings should have 3 ring numbers (ex: 01 12 22)")
+		}
+
+		ring := make([]rune, 3)
+		for i := range ring {
+			n, err := strconv.Atoi(tokens[i])
+			if err != nil || n < 1 || n > 26 {
+				return Settings{}, settingError("invalid ring number: '" + tokens[i] + "'")
+			}
+
+			ring[i] = rune('A' + n - 1)
+		}
+
+		s.Ring = string(ring)
+		tokens = tokens[3:]
+
+		if len(tokens) > 0 && len(tokens[0]) == 3 {
+			s.Window = strings.ToUpper(tokens[0])
+			tokens = tokens[1:]
+		}
+	} else {
+		if len(tokens) < 2 {
+			return Settings{}, settingError("settings should specify the ring and window settings (ex: AAA AAA)")
+		}
+
+		s.Ring, s.Window = strings.ToUpper(tokens[0]), strings.ToUpper(tokens[1])
+		tokens = tokens[2:]
+	}
+
+	s.Plugs = strings.ToUpper(strings.Join(tokens, ""))
+	if err := validatePlugs(s.Plugs); err != nil {
+		return Settings{}, err
+	}
+
+	return s, nil
+}
+
+func parseKeyValue(settings string) (Settings, error) {
+	var s Settings
+	values := make(map[string]string)
+	key := ""
+
+	for _, token := range strings.Fields(settings) {
+		i := strings.Index(token, "=")
+		if i == -1 {
+			// a value with spaces, like "reflector=B Dünn"
+			if key == "" {
+				return Settings{}, settingError("invalid setting: '" + token + "'")
+			}
+
+			values[key] += " " + token
+			continue
+		}
+
+		key = strings.ToLower(token[:i])
+		values[key] = token[i+1:]
+	}
+
+	for key, value := range values {
+		switch key {
+		case "reflector":
+			s.Reflector, _ = parseReflector(strings.Fields(value))
+		case "rotors":
+			rotors := strings.Split(strings.ToUpper(value), ",")
+			if len(rotors) != 3 {
+				return Settings{}, settingError("you should specify 3 rotor ID's")
+			}
+			s.Slow, s.Middle, s.Fast = rotors[0], rotors[1], rotors[2]
+		case "ring":
+			s.Ring = strings.ToUpper(value)
+		case "window":
+			s.Window = strings.ToUpper(value)
+		case "plugs":
+			s.Plugs = strings.ToUpper(strings.NewReplacer(",", "", " ", "").Replace(value))
+		default:
+			return Settings{}, settingError("unknown setting: '" + key + "'")
+		}
+	}
+
+	if s.Reflector == "" || s.Slow == "" {
+		return Settings{}, settingError("settings should specify the reflector and the rotors")
+	}
+
+	if err := validatePlugs(s.Plugs); err != nil {
+		return Settings{}, err
+	}
+
+	return s, nil
+}
+
+func validatePlugs(plugs string) error {
+	if len(plugs)%2 != 0 {
+		return settingError("plugs should be specified in pairs of letters (ex: AB CD)")
+	}
+
+	used := make(map[rune]bool)
+	for _, c := range plugs {
+		if c < 'A' || c > 'Z' {
+			return settingError("plugs should be specified using only uppercase letters from 'A' to 'Z'")
+		}
+
+		if used[c] {
+			return settingError("letter '" + string(c) + "' is plugged more than once")
+		}
+
+		used[c] = true
+	}
+
+	return nil
+}
+
+// Plugs returns the plug pairs of the plugboard of a machine (ex: "ABCD" means A-B and C-D), or an empty string if
+// it has no plugs. Only machines built by this package, with a plugboard that reports its wiring, are supported.
+func Plugs(e Enigma) (string, error) {
+	var wiring [26]uint8
+
+	switch m := e.(type) {
+	case *enigmaImpl:
+		wired, ok := m.plugboard.(parts.Wired)
+		if !ok {
+			return "", errors.New("the plugboard does not report its wiring")
+		}
+
+		for x, v := range wired.Wiring() {
+			wiring[x] = uint8(v - 1)
+		}
+	case *compiledImpl:
+		wiring = m.plug
+	case *Synchronized:
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		return Plugs(m.e)
+	default:
+		return "", errors.New("only machines built by the enigma package have known plugs")
+	}
+
+	var sb strings.Builder
+	for x, y := range wiring {
+		if int(y) > x {
+			sb.WriteByte(byte('A' + x))
+			sb.WriteByte('A' + y)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func plugPairs(plugs string) []string {
+	pairs := make([]string, 0, len(plugs)/2)
+
+	for i := 0; i+1 < len(plugs); i += 2 {
+		pairs = append(pairs, plugs[i:i+2])
+	}
+
+	return pairs
+}
+
+func orDefault(setting string) string {
+	if setting == "" {
+		return "AAA"
+	}
+
+	return setting
+}
@@ -0,0 +1,146 @@
+package enigma_test
+
+import (
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSettingsString(t *testing.T) {
+	expected := enigma.Settings{Reflector: "B", Slow: "III", Middle: "II", Fast: "I", Ring: "ALV", Window: "XYZ", Plugs: "ABCD"}
+
+	var tests = []struct {
+		settings string
+		notation enigma.Notation
+	}{
+		{"B III II I ALV XYZ AB CD", enigma.CompactNotation},
+		{"B III II I ALV XYZ AB CD", enigma.AutoNotation},
+		{"UKW b iii ii i alv xyz ab cd", enigma.AutoNotation},
+		{"B III II I 01 12 22 XYZ AB CD", enigma.KeySheetNotation},
+		{"B III II I 1 12 22 XYZ AB CD", enigma.AutoNotation},
+		{"reflector=B rotors=III,II,I ring=ALV window=XYZ plugs=AB,CD", enigma.KeyValueNotation},
+		{"window=xyz plugs=AB,CD rotors=III,II,I ring=ALV reflector=B", enigma.AutoNotation},
+	}
+
+	for _, test := range tests {
+		actual, err := enigma.ParseSettingsString(test.settings, test.notation)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestParseSettingsStringReflectorWithSpace(t *testing.T) {
+	s, err := enigma.ParseSettingsString("B Dünn V IV III AAA ZZZ", enigma.AutoNotation)
+	assert.NoError(t, err)
+	assert.Equal(t, enigma.Settings{Reflector: "B Dünn", Slow: "V", Middle: "IV", Fast: "III", Ring: "AAA", Window: "ZZZ"}, s)
+
+	s, err = enigma.ParseSettingsString("reflector=C Dünn rotors=V,IV,III", enigma.AutoNotation)
+	assert.NoError(t, err)
+	assert.Equal(t, enigma.Settings{Reflector: "C Dünn", Slow: "V", Middle: "IV", Fast: "III"}, s)
+
+	s, err = enigma.ParseSettingsString("B III II I 01 01 01", enigma.AutoNotation)
+	assert.NoError(t, err)
+	assert.Equal(t, enigma.Settings{Reflector: "B", Slow: "III", Middle: "II", Fast: "I", Ring: "AAA"}, s)
+}
+
+func TestParseSettingsStringError(t *testing.T) {
+	var tests = []struct {
+		settings string
+		notation enigma.Notation
+		message  string
+	}{
+		{"B III II", enigma.CompactNotation, "settings should specify the reflector and 3 rotors (ex: B III II I)"},
+		{"B III II I AAA", enigma.CompactNotation, "settings should specify the ring and window settings (ex: AAA AAA)"},
+		{"B III II I AAA AAA ABC", enigma.CompactNotation, "plugs should be specified in pairs of letters (ex: AB CD)"},
+		{"B III II I AAA AAA A1", enigma.CompactNotation, "plugs should be specified using only uppercase letters from 'A' to 'Z'"},
+		{"B III II I AAA AAA AB AC", enigma.CompactNotation, "letter 'A' is plugged more than once"},
+		{"B III II I 01 12", enigma.KeySheetNotation, "key sheet settings should have 3 ring numbers (ex: 01 12 22)"},
+		{"B III II I 01 12 27", enigma.KeySheetNotation, "invalid ring number: '27'"},
+		{"rotors=III,II", enigma.KeyValueNotation, "you should specify 3 rotor ID's"},
+		{"color=red", enigma.KeyValueNotation, "unknown setting: 'color'"},
+		{"B reflector=C", enigma.KeyValueNotation, "invalid setting: 'B'"},
+		{"ring=AAA", enigma.KeyValueNotation, "settings should specify the reflector and the rotors"},
+		{"B III II I AAA AAA", enigma.Notation(99), "unknown settings notation: 99"},
+	}
+
+	for _, test := range tests {
+		_, err := enigma.ParseSettingsString(test.settings, test.notation)
+		assert.EqualError(t, err, test.message)
+	}
+}
+
+func TestFormatSettingsString(t *testing.T) {
+	s := enigma.Settings{Reflector: "B", Slow: "III", Middle: "II", Fast: "I", Ring: "ALV", Window: "XYZ", Plugs: "ABCD"}
+
+	assert.Equal(t, "B III II I ALV XYZ AB CD", enigma.FormatSettingsString(s, enigma.CompactNotation))
+	assert.Equal(t, "B III II I ALV XYZ AB CD", s.String())
+	assert.Equal(t, "B III II I 01 12 22 XYZ AB CD", enigma.FormatSettingsString(s, enigma.KeySheetNotation))
+	assert.Equal(t, "reflector=B rotors=III,II,I ring=ALV window=XYZ plugs=AB,CD", enigma.FormatSettingsString(s, enigma.KeyValueNotation))
+
+	s = enigma.Settings{Reflector: "C Dünn", Slow: "I", Middle: "II", Fast: "III"}
+	assert.Equal(t, "C Dünn I II III AAA AAA", enigma.FormatSettingsString(s, enigma.AutoNotation))
+	assert.Equal(t, "reflector=C Dünn rotors=I,II,III ring=AAA window=AAA", enigma.FormatSettingsString(s, enigma.KeyValueNotation))
+
+	for _, n := range []enigma.Notation{enigma.CompactNotation, enigma.KeySheetNotation, enigma.KeyValueNotation} {
+		parsed, err := enigma.ParseSettingsString(enigma.FormatSettingsString(s, n), enigma.AutoNotation)
+		assert.NoError(t, err)
+		assert.Equal(t, "C Dünn I II III AAA AAA", parsed.String())
+	}
+}
+
+func TestWithSettings(t *testing.T) {
+	e, err := enigma.WithSettings(enigma.Settings{Reflector: "B", Slow: "I", Middle: "II", Fast: "III", Ring: "BBB", Window: "ABC", Plugs: "ABCD"})
+	assert.NoError(t, err)
+	assert.Equal(t, "B", e.Reflector())
+	assert.Equal(t, "I", e.Slow())
+	assert.Equal(t, "II", e.Middle())
+	assert.Equal(t, "III", e.Fast())
+	assert.Equal(t, "BBB", e.Ring())
+	assert.Equal(t, "ABC", e.Window())
+
+	// 'E' is not plugged, so the plugboard only changes the output of the unplugged machine
+	swap := map[rune]rune{'A': 'B', 'B': 'A', 'C': 'D', 'D': 'C'}
+	plugged, _ := e.Encode('E')
+	e, _ = enigma.WithRotors("I", "II", "III", "B")
+	e.Configure("BBB", "ABC")
+	unplugged, _ := e.Encode('E')
+	if swapped, ok := swap[unplugged]; ok {
+		unplugged = swapped
+	}
+	assert.Equal(t, unplugged, plugged)
+
+	var tests = []struct {
+		settings enigma.Settings
+		message  string
+	}{
+		{enigma.Settings{Reflector: "B", Slow: "X", Middle: "II", Fast: "III"}, "unrecognized rotor ID: 'X'"},
+		{enigma.Settings{Reflector: "X", Slow: "I", Middle: "II", Fast: "III"}, "unknown reflector: 'X'"},
+		{enigma.Settings{Reflector: "B", Slow: "I", Middle: "II", Fast: "III", Plugs: "AA"}, "letter 'A' is plugged more than once"},
+		{enigma.Settings{Reflector: "B", Slow: "I", Middle: "II", Fast: "III", Ring: "A"}, "ring settings should be 3 characters long (ex: AAA)"},
+	}
+
+	for _, test := range tests {
+		_, err := enigma.WithSettings(test.settings)
+		assert.EqualError(t, err, test.message)
+	}
+}
+
+func TestPlugs(t *testing.T) {
+	s := enigma.Settings{Reflector: "B", Slow: "III", Middle: "II", Fast: "I", Plugs: "ZQABCD"}
+
+	for _, m := range compiledAndDefault(t, s) {
+		for _, e := range []enigma.Enigma{m, enigma.NewSynchronized(m)} {
+			plugs, err := enigma.Plugs(e)
+			assert.NoError(t, err)
+			assert.Equal(t, "ABCDQZ", plugs)
+		}
+	}
+
+	plugs, err := enigma.Plugs(enigma.WithDefaults())
+	assert.NoError(t, err)
+	assert.Equal(t, "", plugs)
+
+	_, err = enigma.Plugs(nil)
+	assert.EqualError(t, err, "only machines built by the enigma package have known plugs")
+}
@@ -0,0 +1,208 @@
+package enigma
+
+import (
+	"errors"
+	"sort"
+)
+
+// rotorStack is the stepping state of the rotors: the windows (from 0 to 25) of the slow, middle and fast rotors,
+// and the windows where each rotor is notched
+type rotorStack struct {
+	window  [3]int
+	notches [3][26]bool
+}
+
+// stackOf reads the stepping state of the rotors of the machine. The notches are found by turning each rotor to every
+// window, so any rotor implementation can be used.
+func (e *enigmaImpl) stackOf() rotorStack {
+	var s rotorStack
+
+	for i, r := range e.rotors() {
+		w := r.Window()
+
+		for x := range s.notches[i] {
+			r.SetWindow(rune('A' + x))
+			s.notches[i][x] = r.IsNotched()
+		}
+
+		r.SetWindow(w)
+		s.window[i] = int(w - 'A')
+	}
+
+	return s
+}
+
+// step moves the rotors as in a keypress, with the double step of the middle rotor
+func (s *rotorStack) step() {
+	if s.notches[2][s.window[2]] {
+		if s.notches[1][s.window[1]] {
+			s.window[0] = next(s.window[0])
+		}
+
+		s.window[1] = next(s.window[1])
+	} else if s.notches[1][s.window[1]] {
+		s.window[1] = next(s.window[1])
+		s.window[0] = next(s.window[0])
+	}
+
+	s.window[2] = next(s.window[2])
+}
+
+func next(p int) int {
+	if p == 25 {
+		return 0
+	}
+
+	return p + 1
+}
+
+// advance moves the rotors as in n keypresses. Only the keypresses where the middle rotor can move are simulated;
+// the fast rotor jumps directly between them. Jumps longer than the number of positions are first reduced by the
+// period of the rotors, so the cost never depends on n.
+func (s *rotorStack) advance(n uint64) {
+	if n > 2*Positions {
+		// after this, the rotors are in their cycle (some positions cannot be reached again, due to the double step).
+		// The slow rotor does not move the other ones, so this happens once the middle and fast rotors are in their
+		// cycle, in less than 26 * 26 keypresses.
+		s.advance(26 * 26)
+		n = (n - 26*26) % s.period()
+	}
+
+	for n > 0 {
+		if s.notches[1][s.window[1]] || s.notches[2][s.window[2]] {
+			s.step()
+			n--
+			continue
+		}
+
+		d := s.untilNotch()
+		if d < 0 || uint64(d) >= n {
+			s.window[2] = (s.window[2] + int(n%26)) % 26
+			return
+		}
+
+		s.window[2] = (s.window[2] + d) % 26
+		n -= uint64(d)
+	}
+}
+
+// untilNotch returns the number of keypresses until the fast rotor reaches a notch, or -1 if it has no notches
+func (s *rotorStack) untilNotch() int {
+	for d := 1; d < 26; d++ {
+		if s.notches[2][(s.window[2]+d)%26] {
+			return d
+		}
+	}
+
+	return -1
+}
+
+// period returns the number of keypresses until the rotors return to their current windows. The current windows
+// should be part of the cycle of the rotors.
+//
+// The fast rotor returns to its window after every turn of 26 keypresses, and the middle rotor (that depends only on
+// the fast rotor) after some of these turns. If the slow rotor moved d windows in these turns, it returns to its
+// window after 26 / gcd(d, 26) times as many keypresses.
+func (s rotorStack) period() uint64 {
+	start := s.window
+	var turns uint64
+
+	for {
+		s.advance(26)
+		turns++
+
+		if s.window[1] == start[1] {
+			break
+		}
+	}
+
+	d := (s.window[0] - start[0] + 26) % 26
+	return 26 * turns * uint64(26/gcd(d, 26))
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
+
+// Stepping is the analysis of the rotor movement of a machine, from its current window setting.
+type Stepping struct {
+	// Period is the number of keypresses until the rotors repeat their positions. For the rotors I to V it is
+	// 26 * 25 * 26 = 16900, since the double step skips a position of the middle rotor in each of its turns; the
+	// rotors VI to VIII have two notches and shorter periods.
+	Period uint64
+	// Tail is the number of keypresses before the rotors enter their cycle. It is 1 for the windows that cannot be
+	// reached from any other window due to the double step (ex: "AEA" with rotors I, II and III), and 0 otherwise.
+	Tail uint64
+	// DoubleSteps are the windows, in the cycle, where the next keypress moves the middle rotor for the second time
+	// in a row (along with the slow rotor), in alphabetical order.
+	DoubleSteps []string
+	// NextSlowTurnover is the number of keypresses until the slow rotor moves, or 0 if it never moves.
+	NextSlowTurnover uint64
+}
+
+// AnalyzeStepping computes the period, the double steps and the next turnover of the slow rotor of a machine, from
+// its current window setting. The machine is not changed. Only machines built by this package can be analyzed.
+func AnalyzeStepping(e Enigma) (Stepping, error) {
+	var s rotorStack
+
+	switch m := e.(type) {
+	case *enigmaImpl:
+		s = m.stackOf()
+	case *compiledImpl:
+		s = m.rotorStack
+	case *Synchronized:
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		return AnalyzeStepping(m.e)
+	default:
+		return Stepping{}, errors.New("only machines built by the enigma package can be analyzed")
+	}
+
+	var result Stepping
+
+	// after this, the rotors are in their cycle
+	cycle := s
+	cycle.advance(Positions)
+	result.Period = cycle.period()
+
+	var inCycle [Positions]bool
+	doubleSteps := []string{}
+
+	for i := uint64(0); i < result.Period; i++ {
+		inCycle[cycle.position()] = true
+
+		if cycle.notches[1][cycle.window[1]] {
+			doubleSteps = append(doubleSteps, PositionToWindow(cycle.position()))
+		}
+
+		cycle.step()
+	}
+
+	sort.Strings(doubleSteps)
+	result.DoubleSteps = doubleSteps
+
+	for t := s; !inCycle[t.position()]; t.step() {
+		result.Tail++
+	}
+
+	for t, n := s, uint64(1); n <= result.Tail+result.Period; n++ {
+		slow := t.window[0]
+		t.step()
+
+		if t.window[0] != slow {
+			result.NextSlowTurnover = n
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func (s *rotorStack) position() int {
+	return s.window[0]*676 + s.window[1]*26 + s.window[2]
+}
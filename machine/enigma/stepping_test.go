@@ -0,0 +1,169 @@
+package enigma_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+var steppingSettings = []enigma.Settings{
+	{Reflector: "B", Slow: "I", Middle: "II", Fast: "III", Window: "ADU"},
+	{Reflector: "B", Slow: "III", Middle: "II", Fast: "I", Window: "AEQ"},
+	{Reflector: "C", Slow: "VI", Middle: "VII", Fast: "VIII", Ring: "XKD", Window: "MLY"},
+	{Reflector: "B", Slow: "IV", Middle: "VIII", Fast: "V", Window: "ZZZ"},
+}
+
+func TestAdvance(t *testing.T) {
+	for _, s := range steppingSettings {
+		for _, n := range []int{0, 1, 2, 25, 26, 27, 100, 677, 5000, 20000} {
+			e, _ := enigma.WithSettings(s)
+			e.EncodeMessage(strings.Repeat("A", n), 0)
+
+			for _, m := range compiledAndDefault(t, s) {
+				m.Advance(uint64(n))
+				assert.Equal(t, e.Window(), m.Window(), "%v, n = %d", s, n)
+			}
+		}
+	}
+}
+
+func TestAdvanceLong(t *testing.T) {
+	// rotors I, II and III (in any ring setting) have a period of 26 * 25 * 26 keypresses
+	for _, m := range compiledAndDefault(t, steppingSettings[0]) {
+		m.Advance(enigma.Positions)
+		window := m.Window()
+
+		m.Advance(16900 * 1000000000)
+		assert.Equal(t, window, m.Window())
+
+		var n uint64 = 1<<64 - 1
+		m.Advance(n)
+		other, _ := enigma.Compile(m)
+		assert.NoError(t, other.SetWindow(window))
+		other.Advance(n % 16900)
+		assert.Equal(t, other.Window(), m.Window())
+	}
+}
+
+func TestSeekTo(t *testing.T) {
+	for _, s := range steppingSettings {
+		e, _ := enigma.WithSettings(s)
+		expected := e.EncodeMessage(strings.Repeat("ENIGMA", 100), 0)
+
+		for _, m := range compiledAndDefault(t, s) {
+			m.SeekTo(300)
+			assert.Equal(t, expected[300:310], m.EncodeMessage("ENIGMAENIG", 0))
+
+			// back to the start
+			m.SeekTo(6)
+			assert.Equal(t, expected[6:12], m.EncodeMessage("ENIGMA", 0))
+		}
+	}
+}
+
+func TestAnalyzeStepping(t *testing.T) {
+	tests := []struct {
+		window      string
+		period      uint64
+		tail        uint64
+		doubleSteps int
+		turnover    uint64
+	}{
+		{"ADU", 16900, 0, 26, 3},
+		{"AAA", 16900, 0, 26, 22 + 26*3 + 1},
+		{"AEA", 16900, 1, 26, 1},
+		{"ZZZ", 16900, 0, 26, 23 + 26*4 + 1},
+	}
+
+	for _, test := range tests {
+		s := steppingSettings[0]
+		s.Window = test.window
+
+		for _, m := range compiledAndDefault(t, s) {
+			stepping, err := enigma.AnalyzeStepping(m)
+			assert.NoError(t, err)
+			assert.Equal(t, test.window, m.Window())
+			assert.Equal(t, test.period, stepping.Period, test.window)
+			assert.Equal(t, test.tail, stepping.Tail, test.window)
+			assert.Len(t, stepping.DoubleSteps, test.doubleSteps, test.window)
+			assert.Equal(t, test.turnover, stepping.NextSlowTurnover, test.window)
+
+			for _, w := range stepping.DoubleSteps {
+				assert.Equal(t, byte('E'), w[1], test.window)
+			}
+		}
+	}
+}
+
+func TestAnalyzeSteppingEncoding(t *testing.T) {
+	// check every result against the windows of a machine that encodes letter by letter
+	for _, s := range steppingSettings {
+		e, _ := enigma.WithSettings(s)
+		stepping, err := enigma.AnalyzeStepping(e)
+		assert.NoError(t, err)
+
+		windows := []string{e.Window()}
+		for i := uint64(0); i < stepping.Tail+2*stepping.Period; i++ {
+			e.Encode('A')
+			windows = append(windows, e.Window())
+		}
+
+		start := windows[stepping.Tail]
+		assert.Equal(t, start, windows[stepping.Tail+stepping.Period], "%v", s)
+		for i := uint64(1); i < stepping.Period; i++ {
+			if windows[stepping.Tail+i] == start {
+				t.Errorf("%v: the rotors repeat after %d keypresses, expected %d", s, i, stepping.Period)
+				break
+			}
+		}
+
+		if stepping.Tail > 0 {
+			for _, w := range windows[stepping.Tail:] {
+				assert.NotEqual(t, windows[0], w, "%v", s)
+			}
+		}
+
+		doubleSteps := 0
+		for i, w := range windows[stepping.Tail : stepping.Tail+stepping.Period] {
+			next := windows[int(stepping.Tail)+i+1]
+			if w[1] != next[1] && w[0] != next[0] {
+				doubleSteps++
+				assert.Contains(t, stepping.DoubleSteps, w, "%v", s)
+			}
+		}
+		assert.Len(t, stepping.DoubleSteps, doubleSteps, "%v", s)
+
+		for i := uint64(1); i < stepping.NextSlowTurnover; i++ {
+			assert.Equal(t, windows[0][0], windows[i][0], "%v", s)
+		}
+		assert.NotEqual(t, windows[0][0], windows[stepping.NextSlowTurnover][0], "%v", s)
+	}
+}
+
+func TestAnalyzeSteppingError(t *testing.T) {
+	_, err := enigma.AnalyzeStepping(nil)
+	assert.EqualError(t, err, "only machines built by the enigma package can be analyzed")
+}
+
+func compiledAndDefault(t *testing.T, s enigma.Settings) []enigma.Enigma {
+	e, err := enigma.WithSettings(s)
+	assert.NoError(t, err)
+
+	c, err := enigma.Compile(e)
+	assert.NoError(t, err)
+
+	e2, err := enigma.WithSettings(s)
+	assert.NoError(t, err)
+
+	return []enigma.Enigma{e, c, enigma.NewSynchronized(e2)}
+}
+
+func BenchmarkAdvance(b *testing.B) {
+	e := enigma.WithDefaults()
+
+	for i := 0; i < b.N; i++ {
+		e.Advance(1000000)
+	}
+}
@@ -0,0 +1,156 @@
+package enigma
+
+import (
+	"io"
+)
+
+// NonLetters controls what a Stream does with the bytes that are not ASCII letters.
+type NonLetters int
+
+const (
+	// PassNonLetters copies the non-letters unchanged, without moving the rotors.
+	PassNonLetters NonLetters = iota
+	// DropNonLetters removes the non-letters from the output.
+	DropNonLetters
+	// RejectNonLetters stops at the first non-letter, with an InvalidRuneError.
+	RejectNonLetters
+)
+
+// Stream encodes bytes with a machine, in the shape of crypto/cipher.Stream: each letter moves the rotors and is
+// replaced by its encoding, keeping its case, so the same text is decoded by a stream of a machine with the same
+// settings. What happens with the other bytes depends on the NonLetters policy.
+//
+// Only the policy PassNonLetters keeps the length of the text, as cipher.Stream requires; with the other policies,
+// use Transform, or the Reader and Writer of the stream.
+type Stream struct {
+	e      Enigma
+	policy NonLetters
+}
+
+// NewStream returns a stream that encodes with e, from its current window, and handles the non-letters with the
+// policy.
+func NewStream(e Enigma, policy NonLetters) *Stream {
+	return &Stream{e: e, policy: policy}
+}
+
+// XORKeyStream encodes src into dst, as cipher.Stream does; dst and src may overlap entirely. Like cipher.Stream, it
+// panics if dst is shorter than src, and also if the policy is not PassNonLetters and src has a non-letter, since the
+// length of the text cannot change and no error can be returned.
+func (s *Stream) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("enigma: output smaller than input")
+	}
+
+	if s.policy != PassNonLetters {
+		for i, b := range src {
+			if !isLetter(b) {
+				panic(nonLetterError(b, int64(i)))
+			}
+		}
+	}
+
+	s.Transform(dst, src)
+}
+
+// Transform encodes src into dst, which should be at least as long as src, and returns the number of bytes written.
+// dst and src may overlap entirely. With RejectNonLetters, it stops at the first non-letter and returns an error;
+// the bytes before it are encoded and written.
+func (s *Stream) Transform(dst, src []byte) (n int, err error) {
+	return s.transform(dst, src, 0)
+}
+
+// transform is Transform for src starting at the offset of a longer text, used in the errors
+func (s *Stream) transform(dst, src []byte, offset int64) (n int, err error) {
+	if len(dst) < len(src) {
+		return 0, io.ErrShortBuffer
+	}
+
+	for i, b := range src {
+		switch {
+		case isLetter(b):
+			s.e.EncodeBytes(dst[n:n+1], src[i:i+1])
+			if b >= 'a' {
+				dst[n] += 'a' - 'A'
+			}
+		case s.policy == PassNonLetters:
+			dst[n] = b
+		case s.policy == DropNonLetters:
+			continue
+		default:
+			return n, nonLetterError(b, offset+int64(i))
+		}
+
+		n++
+	}
+
+	return n, nil
+}
+
+// Reader returns a reader that encodes the bytes read from r.
+func (s *Stream) Reader(r io.Reader) io.Reader {
+	return &streamReader{s: s, r: r}
+}
+
+// Writer returns a writer that encodes the bytes before writing them to w. The number of bytes returned by Write is
+// the number of bytes of its input that were encoded, even when non-letters are dropped.
+func (s *Stream) Writer(w io.Writer) io.Writer {
+	return &streamWriter{s: s, w: w}
+}
+
+type streamReader struct {
+	s      *Stream
+	r      io.Reader
+	offset int64
+}
+
+func (sr *streamReader) Read(p []byte) (int, error) {
+	for {
+		n, err := sr.r.Read(p)
+
+		encoded, encodeErr := sr.s.transform(p, p[:n], sr.offset)
+		sr.offset += int64(n)
+		if encodeErr != nil {
+			return encoded, encodeErr
+		}
+
+		// only non-letters were read and dropped, so read again instead of returning nothing
+		if encoded > 0 || n == 0 || err != nil {
+			return encoded, err
+		}
+	}
+}
+
+type streamWriter struct {
+	s      *Stream
+	w      io.Writer
+	buf    []byte
+	offset int64
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	if cap(sw.buf) < len(p) {
+		sw.buf = make([]byte, len(p))
+	}
+
+	n, encodeErr := sw.s.transform(sw.buf[:len(p)], p, sw.offset)
+	sw.offset += int64(len(p))
+
+	if _, err := sw.w.Write(sw.buf[:n]); err != nil {
+		return 0, err
+	}
+
+	if encodeErr != nil {
+		// the bytes before the non-letter were written
+		return n, encodeErr
+	}
+
+	return len(p), nil
+}
+
+func isLetter(b byte) bool {
+	return b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z'
+}
+
+func nonLetterError(b byte, i int64) error {
+	return &InvalidRuneError{Position: int(i), Rune: rune(b)}
+}
@@ -0,0 +1,122 @@
+package enigma_test
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ cipher.Stream = (*enigma.Stream)(nil)
+
+func TestStreamPolicies(t *testing.T) {
+	tests := []struct {
+		policy   enigma.NonLetters
+		expected string
+		err      string
+	}{
+		{enigma.PassNonLetters, "Vwjbfi, 2 gxkxeosz!", ""},
+		{enigma.DropNonLetters, "Vwjbfigxkxeosz", ""},
+		{enigma.RejectNonLetters, "Vwjbfi", "the character ',' at 6 cannot be encoded"},
+	}
+
+	for _, test := range tests {
+		for _, m := range compiledAndDefault(t, enigma.Settings{Reflector: "B", Slow: "III", Middle: "II", Fast: "I"}) {
+			s := enigma.NewStream(m, test.policy)
+			dst := make([]byte, 19)
+
+			n, err := s.Transform(dst, []byte("Enigma, 2 machines!"))
+			assert.Equal(t, test.expected, string(dst[:n]))
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		}
+	}
+}
+
+func TestStreamXORKeyStream(t *testing.T) {
+	text := []byte("Enigma, 2 machines!")
+
+	for _, m := range compiledAndDefault(t, steppingSettings[2]) {
+		// in place, like a stream cipher
+		buf := append([]byte{}, text...)
+		enigma.NewStream(m, enigma.PassNonLetters).XORKeyStream(buf, buf)
+
+		e, _ := enigma.WithSettings(steppingSettings[2])
+		assert.Equal(t, e.EncodeMessageWith(string(text), enigma.EncodeOptions{Passthrough: true, PreserveCase: true}), string(buf))
+
+		// the same stream decodes
+		assert.NoError(t, m.SetWindow(steppingSettings[2].Window))
+		enigma.NewStream(m, enigma.PassNonLetters).XORKeyStream(buf, buf)
+		assert.Equal(t, text, buf)
+
+		assert.PanicsWithValue(t, "enigma: output smaller than input", func() {
+			enigma.NewStream(m, enigma.PassNonLetters).XORKeyStream(buf[:3], buf)
+		})
+
+		assert.Panics(t, func() {
+			enigma.NewStream(m, enigma.DropNonLetters).XORKeyStream(buf, buf)
+		})
+	}
+
+	_, err := enigma.NewStream(enigma.WithDefaults(), enigma.PassNonLetters).Transform(nil, text)
+	assert.Equal(t, io.ErrShortBuffer, err)
+}
+
+func TestStreamInPlaceDrop(t *testing.T) {
+	s := enigma.NewStream(enigma.WithDefaults(), enigma.DropNonLetters)
+	buf := []byte("a b c d e f g h")
+
+	n, err := s.Transform(buf, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.ToLower(enigma.WithDefaults().EncodeMessage("abcdefgh", 0)), string(buf[:n]))
+}
+
+func TestStreamReader(t *testing.T) {
+	text := strings.Repeat("Enigma, 2 machines! ", 50)
+	e := enigma.WithDefaults()
+	expected := e.EncodeMessageWith(text, enigma.EncodeOptions{})
+
+	// a reader that returns one byte at a time, so some reads are only dropped non-letters
+	r := enigma.NewStream(enigma.WithDefaults(), enigma.DropNonLetters).Reader(iotest.OneByteReader(strings.NewReader(text)))
+	output, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, strings.ToUpper(string(output)))
+
+	r = enigma.NewStream(enigma.WithDefaults(), enigma.RejectNonLetters).Reader(iotest.HalfReader(strings.NewReader("abcdefghij k")))
+	output, err = io.ReadAll(r)
+	assert.EqualError(t, err, "the character ' ' at 10 cannot be encoded")
+	assert.Equal(t, strings.ToLower(enigma.WithDefaults().EncodeMessage("abcdefghij", 0)), string(output))
+}
+
+func TestStreamWriter(t *testing.T) {
+	text := "Enigma, 2 machines!"
+	e := enigma.WithDefaults()
+	expected := e.EncodeMessageWith(text, enigma.EncodeOptions{Passthrough: true, PreserveCase: true})
+
+	var buf bytes.Buffer
+	w := enigma.NewStream(enigma.WithDefaults(), enigma.PassNonLetters).Writer(&buf)
+	for _, part := range []string{"Enig", "ma, 2 mach", "ines!"} {
+		n, err := w.Write([]byte(part))
+		assert.NoError(t, err)
+		assert.Equal(t, len(part), n)
+	}
+	assert.Equal(t, expected, buf.String())
+
+	buf.Reset()
+	w = enigma.NewStream(enigma.WithDefaults(), enigma.RejectNonLetters).Writer(&buf)
+	n, err := w.Write([]byte("Enig"))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n)
+	n, err = w.Write([]byte("ma, 2"))
+	assert.EqualError(t, err, "the character ',' at 6 cannot be encoded")
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "Vwjbfi", buf.String())
+}
@@ -0,0 +1,262 @@
+package enigma
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/ibraimgm/enigma/machine/parts"
+)
+
+// Synchronized is a machine that can be shared between goroutines. Each method call holds a lock on the machine, so
+// the keypresses of a call are never mixed with the ones of another call.
+//
+// A sequence of calls that depend on each other, like setting the window and encoding a message, must be made with
+// Do, since another goroutine can move the rotors between two calls.
+type Synchronized struct {
+	mu sync.Mutex
+	e  Enigma
+}
+
+// NewSynchronized wraps the machine e. After this, e should only be used through the wrapper.
+func NewSynchronized(e Enigma) *Synchronized {
+	return &Synchronized{e: e}
+}
+
+// Do calls f with the wrapped machine, holding the lock until f returns. The machine should not be used after f
+// returns, and f should not call the methods of the wrapper.
+func (s *Synchronized) Do(f func(e Enigma)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f(s.e)
+}
+
+func (s *Synchronized) Reflector() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.Reflector()
+}
+
+func (s *Synchronized) Slow() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.Slow()
+}
+
+func (s *Synchronized) Middle() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.Middle()
+}
+
+func (s *Synchronized) Fast() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.Fast()
+}
+
+func (s *Synchronized) Window() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.Window()
+}
+
+func (s *Synchronized) SetWindow(settings string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.SetWindow(settings)
+}
+
+func (s *Synchronized) Ring() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.Ring()
+}
+
+func (s *Synchronized) SetRing(settings string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.SetRing(settings)
+}
+
+func (s *Synchronized) Configure(ringSetting, windowSetting string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.Configure(ringSetting, windowSetting)
+}
+
+func (s *Synchronized) Encode(input rune) (rune, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.Encode(input)
+}
+
+func (s *Synchronized) EncodeBytes(dst, src []byte) (n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.EncodeBytes(dst, src)
+}
+
+func (s *Synchronized) EncodeSignals(dst, src []parts.Signal) (n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.EncodeSignals(dst, src)
+}
+
+func (s *Synchronized) EncodeMessage(message string, blockSize uint) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.EncodeMessage(message, blockSize)
+}
+
+func (s *Synchronized) EncodeMessageWith(message string, options EncodeOptions) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.EncodeMessageWith(message, options)
+}
+
+func (s *Synchronized) EncodeStrict(message string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.EncodeStrict(message)
+}
+
+func (s *Synchronized) Advance(n uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.e.Advance(n)
+}
+
+func (s *Synchronized) SeekTo(n uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.e.SeekTo(n)
+}
+
+func (s *Synchronized) Unstep() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.Unstep()
+}
+
+func (s *Synchronized) Backspace(n uint64) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.e.Backspace(n)
+}
+
+// Pool keeps machines ready for concurrent requests, grouped by settings. Each machine is used by one goroutine at a
+// time: Get takes a machine from the pool (or builds a new one), and Put gives it back when the request is done.
+//
+// The first machine of each settings is compiled (see Compile) and kept as a template; the next ones are copies of
+// the template, which is much faster than building them from the parts. A Pool is safe for concurrent use, and its
+// zero value is ready to use.
+type Pool struct {
+	// MaxIdle is the number of machines given back that are kept for each settings; the other ones are dropped, so a
+	// burst of requests does not keep its machines alive forever. Zero means runtime.GOMAXPROCS(0).
+	MaxIdle int
+
+	mu        sync.Mutex
+	templates map[Settings]Enigma
+	free      map[Settings][]Enigma
+	taken     map[Enigma]Settings
+}
+
+// NewPool returns an empty pool.
+func NewPool() *Pool {
+	return &Pool{}
+}
+
+// Get returns a machine with the settings, with the rotors at the window setting. The machine should be given
+// back with Put when it is no longer used.
+func (p *Pool) Get(settings Settings) (Enigma, error) {
+	key := settings
+	key.Window = ""
+	key.Ring = orDefault(key.Ring)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.templates == nil {
+		p.templates = make(map[Settings]Enigma)
+		p.free = make(map[Settings][]Enigma)
+		p.taken = make(map[Enigma]Settings)
+	}
+
+	var e Enigma
+
+	if free := p.free[key]; len(free) > 0 {
+		e = free[len(free)-1]
+		p.free[key] = free[:len(free)-1]
+	} else {
+		template, ok := p.templates[key]
+		if !ok {
+			m, err := WithSettings(key)
+			if err != nil {
+				return nil, err
+			}
+
+			if template, err = Compile(m); err != nil {
+				return nil, err
+			}
+
+			p.templates[key] = template
+		}
+
+		e, _ = Compile(template)
+	}
+
+	if err := e.Configure(key.Ring, orDefault(settings.Window)); err != nil {
+		p.release(key, e)
+		return nil, err
+	}
+
+	p.taken[e] = key
+	return e, nil
+}
+
+// Put gives back a machine returned by Get. Machines that did not come from the pool, or that were already given
+// back, are ignored.
+func (p *Pool) Put(e Enigma) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.taken[e]
+	if !ok {
+		return
+	}
+
+	delete(p.taken, e)
+	p.release(key, e)
+}
+
+// release keeps a machine for the next Get, unless there are enough idle machines with the same settings
+func (p *Pool) release(key Settings, e Enigma) {
+	limit := p.MaxIdle
+	if limit <= 0 {
+		limit = runtime.GOMAXPROCS(0)
+	}
+
+	if len(p.free[key]) < limit {
+		p.free[key] = append(p.free[key], e)
+	}
+}
@@ -0,0 +1,145 @@
+package enigma_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+const goroutines = 8
+
+func TestSynchronizedStress(t *testing.T) {
+	for _, m := range compiledAndDefault(t, steppingSettings[0]) {
+		s := enigma.NewSynchronized(m)
+
+		var wg sync.WaitGroup
+		for i := 0; i < goroutines; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < 100; j++ {
+					s.Encode('A')
+					s.EncodeMessage("ENIGMA", 0)
+				}
+			}()
+		}
+		wg.Wait()
+
+		// every keypress was counted, no matter the order
+		e, _ := enigma.WithSettings(steppingSettings[0])
+		e.Advance(goroutines * 100 * 7)
+		assert.Equal(t, e.Window(), s.Window())
+	}
+}
+
+func TestSynchronizedDo(t *testing.T) {
+	e, _ := enigma.WithSettings(steppingSettings[0])
+	expected := e.EncodeMessage(strings.Repeat("ENIGMA", 10), 0)
+
+	m, _ := enigma.WithSettings(steppingSettings[0])
+	s := enigma.NewSynchronized(m)
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				s.Do(func(m enigma.Enigma) {
+					assert.NoError(t, m.SetWindow(steppingSettings[0].Window))
+					assert.Equal(t, expected, m.EncodeMessage(strings.Repeat("ENIGMA", 10), 0))
+				})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPool(t *testing.T) {
+	p := enigma.NewPool()
+
+	e, err := p.Get(steppingSettings[2])
+	assert.NoError(t, err)
+	assert.Equal(t, steppingSettings[2].Window, e.Window())
+	assert.Equal(t, steppingSettings[2].Ring, e.Ring())
+
+	// a machine given back is reused, with the rotors back at the window setting
+	e.EncodeMessage("ENIGMA", 0)
+	p.Put(e)
+	p.Put(e)
+
+	s := steppingSettings[2]
+	s.Window = "ABC"
+	other, err := p.Get(s)
+	assert.NoError(t, err)
+	assert.True(t, e == other)
+	assert.Equal(t, "ABC", other.Window())
+
+	// the free machines are kept by settings
+	another, err := p.Get(s)
+	assert.NoError(t, err)
+	assert.False(t, e == another)
+
+	p.Put(enigma.WithDefaults())
+
+	_, err = p.Get(enigma.Settings{Reflector: "B", Slow: "X", Middle: "II", Fast: "III"})
+	assert.EqualError(t, err, "unrecognized rotor ID: 'X'")
+
+	s.Window = "A"
+	_, err = p.Get(s)
+	assert.EqualError(t, err, "window settings should be 3 characters long (ex: AAA)")
+}
+
+func TestPoolMaxIdle(t *testing.T) {
+	p := &enigma.Pool{MaxIdle: 1}
+
+	e1, err := p.Get(steppingSettings[0])
+	assert.NoError(t, err)
+	e2, err := p.Get(steppingSettings[0])
+	assert.NoError(t, err)
+
+	// only one of the machines is kept
+	p.Put(e1)
+	p.Put(e2)
+
+	other, err := p.Get(steppingSettings[0])
+	assert.NoError(t, err)
+	assert.True(t, other == e1)
+
+	other, err = p.Get(steppingSettings[0])
+	assert.NoError(t, err)
+	assert.False(t, other == e1 || other == e2)
+}
+
+func TestPoolStress(t *testing.T) {
+	var p enigma.Pool
+
+	expected := make([]string, len(steppingSettings))
+	for i, s := range steppingSettings {
+		e, _ := enigma.WithSettings(s)
+		expected[i] = e.EncodeMessage(strings.Repeat("ENIGMA", 10), 0)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				k := (i + j) % len(steppingSettings)
+
+				e, err := p.Get(steppingSettings[k])
+				assert.NoError(t, err)
+				assert.Equal(t, expected[k], e.EncodeMessage(strings.Repeat("ENIGMA", 10), 0))
+				p.Put(e)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,87 @@
+package enigma_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ibraimgm/enigma/machine/enigma"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnstep(t *testing.T) {
+	for _, s := range steppingSettings {
+		for _, m := range compiledAndDefault(t, s) {
+			windows := []string{m.Window()}
+			for i := 0; i < 700; i++ {
+				m.Encode('A')
+				windows = append(windows, m.Window())
+			}
+
+			for i := len(windows) - 2; i >= 0; i-- {
+				assert.True(t, m.Unstep())
+				assert.Equal(t, windows[i], m.Window())
+			}
+
+			assert.False(t, m.Unstep())
+			assert.Equal(t, windows[0], m.Window())
+		}
+	}
+}
+
+func TestUnstepDoubleStep(t *testing.T) {
+	// both "AEW" (with a double step) and "BFW" go to "BFX"
+	e, _ := enigma.WithRotors("I", "II", "III", "B")
+	e.SetWindow("ADV")
+	e.EncodeMessage("AA", 0)
+	assert.Equal(t, "BFX", e.Window())
+	assert.True(t, e.Unstep())
+	assert.Equal(t, "AEW", e.Window())
+
+	e.SetWindow("BFW")
+	e.Encode('A')
+	assert.Equal(t, "BFX", e.Window())
+	assert.True(t, e.Unstep())
+	assert.Equal(t, "BFW", e.Window())
+}
+
+func TestBackspace(t *testing.T) {
+	for _, m := range compiledAndDefault(t, steppingSettings[0]) {
+		m.EncodeMessage(strings.Repeat("A", 50), 0)
+		m.Advance(10)
+		assert.Equal(t, uint64(20), m.Backspace(20))
+
+		m.SetWindow("ADU")
+		m.Advance(40)
+		assert.Equal(t, uint64(40), m.Backspace(50))
+		assert.Equal(t, "ADU", m.Window())
+	}
+}
+
+func TestEncodeMessageWithBackspace(t *testing.T) {
+	var tests = []struct {
+		original string
+		options  enigma.EncodeOptions
+		expected string
+	}{
+		{"ENIGMAX\bMACHINE", enigma.EncodeOptions{BlockSize: 5, Backspace: true}, "ENIGMAMACHINE"},
+		{"ENIGMAXX\x7f\x7f MACHINE", enigma.EncodeOptions{BlockSize: 5, Backspace: true}, "ENIGMA MACHINE"},
+		{"Enigma,\b\b machine", enigma.EncodeOptions{Passthrough: true, Backspace: true}, "Enigm machine"},
+		{"\b\bEnigma", enigma.EncodeOptions{Backspace: true}, "Enigma"},
+		{"Enigma\b", enigma.EncodeOptions{}, "Enigma"},
+	}
+
+	e := enigma.WithDefaults()
+
+	for _, test := range tests {
+		e.Configure("", "")
+		expected := e.EncodeMessageWith(test.expected, test.options)
+
+		e.Configure("", "")
+		actual := e.EncodeMessageWith(test.original, test.options)
+		assert.Equal(t, expected, actual)
+		assert.NotContains(t, actual, "\b")
+	}
+
+	e.Configure("", "")
+	assert.Equal(t, "VWJBF IGXKX EOS", e.EncodeMessageWith("ENIGMAX\bMACHINE", enigma.EncodeOptions{BlockSize: 5, Backspace: true}))
+}
@@ -49,3 +49,13 @@ func (board *plugboardImpl) Translate(input Signal) Signal {
 
 	return input
 }
+
+func (board *plugboardImpl) Wiring() [26]Signal {
+	var wiring [26]Signal
+
+	for i := range wiring {
+		wiring[i] = board.Translate(Signal(i + 1))
+	}
+
+	return wiring
+}
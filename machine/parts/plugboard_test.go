@@ -53,3 +53,13 @@ func plugboardTestAux(t *testing.T, board parts.Plugboard) {
 		assert.Equal(t, expected, actual)
 	}
 }
+
+func TestPlugboardWiring(t *testing.T) {
+	wiring := parts.CreatePlugboard("AZ").(parts.Wired).Wiring()
+	assert.Equal(t, parts.Signal(26), wiring[0])
+	assert.Equal(t, parts.Signal(2), wiring[1])
+	assert.Equal(t, parts.Signal(1), wiring[25])
+
+	wiring = parts.Reflectors["B"].(parts.Wired).Wiring()
+	assert.Equal(t, parts.Signal(25), wiring[0])
+}
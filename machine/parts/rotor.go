@@ -2,7 +2,6 @@ package parts
 
 import (
 	"errors"
-	"math"
 )
 
 // Rotor also known as 'scrambler' is the main piece that controls how the text
@@ -21,6 +20,21 @@ type Rotor interface {
 	Reverse(input Signal) Signal
 }
 
+// ErrUnknownRotor is the error returned by GetRotor for an unknown rotor ID. The returned error wraps it, with the
+// ID in the message, so it should be checked with errors.Is.
+var ErrUnknownRotor = errors.New("unknown rotor")
+
+// unknownRotorError is the error of an unknown rotor ID
+type unknownRotorError string
+
+func (e unknownRotorError) Error() string {
+	return "unrecognized rotor ID: '" + string(e) + "'"
+}
+
+func (e unknownRotorError) Unwrap() error {
+	return ErrUnknownRotor
+}
+
 // GetRotor returns a default implementation of one of the historical rotors.
 // The id must be one of the roman numerals, from I to VIII.
 // Each call to GetRotor returns a new instance.
@@ -44,7 +58,7 @@ func GetRotor(id string) (Rotor, error) {
 	case "VIII":
 		return CreateRotor("VIII", "FKQHTLXOCBJSPDZRAMEWNIUYGV", "ZM"), nil
 	default:
-		return nil, errors.New("unrecognized rotor ID: '" + id + "'")
+		return nil, unknownRotorError(id)
 	}
 }
 
@@ -53,6 +67,7 @@ type rotorImpl struct {
 	position int
 	ring     int
 	sequence []int
+	inverse  []int
 	notches  []int
 }
 
@@ -68,12 +83,24 @@ func CreateRotor(rotorID string, sequence string, notches string) Rotor {
 
 	sequenceRunes := []rune(sequence)
 	sequenceInt := make([]int, len(sequenceRunes))
+	inverseInt := make([]int, 27)
 
 	for i := range sequenceRunes {
 		sequenceInt[i] = charToInt(sequenceRunes[i])
+
+		if sequenceInt[i] != -1 {
+			inverseInt[sequenceInt[i]] = i + 1
+		}
 	}
 
-	return Rotor(&rotorImpl{position: 1, ring: 1, id: rotorID, sequence: sequenceInt, notches: notchesInt})
+	return Rotor(&rotorImpl{
+		position: 1,
+		ring:     1,
+		id:       rotorID,
+		sequence: sequenceInt,
+		inverse:  inverseInt,
+		notches:  notchesInt,
+	})
 }
 
 func (r *rotorImpl) ID() string {
@@ -89,8 +116,12 @@ func (r *rotorImpl) SetWindow(value rune) {
 }
 
 func (r *rotorImpl) Move(step int) {
-	newPos := r.position - 1 + step
-	r.position = int(math.Mod(float64(newPos+26), 26)) + 1
+	newPos := (r.position - 1 + step) % 26
+	if newPos < 0 {
+		newPos += 26
+	}
+
+	r.position = newPos + 1
 }
 
 func (r *rotorImpl) Ring() rune {
@@ -122,14 +153,27 @@ func (r *rotorImpl) Scramble(input Signal) Signal {
 
 func (r *rotorImpl) Reverse(input Signal) Signal {
 	from := fixAlpha(int(input) - r.ring + r.position)
+	from = r.inverse[from]
+	from = fixAlpha(from + r.ring - r.position)
+	return Signal(from)
+}
+
+func (r *rotorImpl) Wiring() [26]Signal {
+	var wiring [26]Signal
 
 	for i, v := range r.sequence {
-		if v == from {
-			from = i + 1
-			break
-		}
+		wiring[i] = Signal(v)
 	}
 
-	from = fixAlpha(from + r.ring - r.position)
-	return Signal(from)
+	return wiring
+}
+
+func (r *rotorImpl) Notches() []rune {
+	notches := make([]rune, len(r.notches))
+
+	for i, v := range r.notches {
+		notches[i] = intToChar(v)
+	}
+
+	return notches
 }
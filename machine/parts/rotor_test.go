@@ -1,6 +1,7 @@
 package parts_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -18,6 +19,7 @@ func TestRotorCreationID(t *testing.T) {
 
 	_, err := parts.GetRotor("XX")
 	assert.EqualError(t, err, "unrecognized rotor ID: 'XX'")
+	assert.True(t, errors.Is(err, parts.ErrUnknownRotor))
 }
 
 type rotorStepTable struct {
@@ -218,3 +220,22 @@ func TestRotorScrambleMultipleRing(t *testing.T) {
 
 	rotorScrambleTableRunner(t, tests)
 }
+
+func TestRotorWiring(t *testing.T) {
+	r, _ := parts.GetRotor("VI")
+	r.SetWindow('K')
+	r.SetRing('C')
+
+	wired, ok := r.(parts.WiredRotor)
+	assert.True(t, ok)
+	assert.Equal(t, []rune{'Z', 'M'}, wired.Notches())
+
+	wiring := wired.Wiring()
+	assert.Equal(t, parts.Signal(10), wiring[0])  // A -> J
+	assert.Equal(t, parts.Signal(23), wiring[25]) // Z -> W
+
+	// the reverse of each letter is consistent with the wiring, in any position
+	for i := 1; i <= 26; i++ {
+		assert.Equal(t, parts.Signal(i), r.Reverse(r.Scramble(parts.Signal(i))))
+	}
+}
@@ -0,0 +1,15 @@
+package parts
+
+// Wired is implemented by the default rotors, reflectors and plugboards, to report their internal connections, so a
+// machine can precompute its encoding. The wiring maps each signal to its output (index 0 is the output of signal 1),
+// for rotors with the window and the ring settings in 'A'.
+type Wired interface {
+	Wiring() [26]Signal
+}
+
+// WiredRotor is a rotor that reports its wiring and the window letters of its notches.
+type WiredRotor interface {
+	Rotor
+	Wired
+	Notches() []rune
+}
@@ -0,0 +1,231 @@
+// Package text provides the conventions used by the historical enigma operators to prepare a message before
+// encoding it, and to make a decoded message readable again.
+package text
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Convention describes the rules used to convert free text into something that can be typed on the enigma keyboard.
+// Prepare converts the text before encoding, and Restore does a best-effort job of converting the decoded text back
+// into something more readable. Since the conversion loses information, Restore is not a perfect inverse of Prepare.
+type Convention interface {
+	Name() string
+	Prepare(message string) string
+	Restore(message string) string
+}
+
+type digitMode int
+
+const (
+	digitsSpelled digitMode = iota
+	digitsTopRow
+)
+
+// the spelled out digits, as written by the operators ('CH' is already replaced by 'Q', and 'Ü' by 'UE', so a digit
+// and its word are encoded the same way)
+var spelledDigits = []string{"NULL", "EINS", "ZWO", "DREI", "VIER", "FUENF", "SEQS", "SIEBEN", "AQT", "NEUN"}
+
+// the top row of the enigma keyboard, from '1' to '0'
+const topRow = "QWERTZUIOP"
+
+type conventionImpl struct {
+	name        string
+	punctuation map[rune]string
+	digits      digitMode
+}
+
+// Conventions is a map with the historical conventions used to prepare the messages.
+// The valid keys are "heer" (Heer and Luftwaffe, spelling the digits), "heer-y" (Heer and Luftwaffe, writing the
+// digits with the keyboard top row between two 'Y') and "kriegsmarine".
+var Conventions = map[string]Convention{
+	"heer": Convention(&conventionImpl{
+		name:        "heer",
+		punctuation: map[rune]string{'.': "X", ':': "XX", ',': "ZZ", '?': "FRAQ", '-': "YY"},
+		digits:      digitsSpelled,
+	}),
+	"heer-y": Convention(&conventionImpl{
+		name:        "heer-y",
+		punctuation: map[rune]string{'.': "X", ':': "XX", ',': "ZZ", '?': "FRAQ", '-': "YY"},
+		digits:      digitsTopRow,
+	}),
+	"kriegsmarine": Convention(&conventionImpl{
+		name:        "kriegsmarine",
+		punctuation: map[rune]string{'.': "X", ':': "XX", ',': "Y", '?': "UD", '-': "YY"},
+		digits:      digitsSpelled,
+	}),
+}
+
+func (c *conventionImpl) Name() string {
+	return c.name
+}
+
+func (c *conventionImpl) Prepare(message string) string {
+	var sb strings.Builder
+	runes := []rune(message)
+
+	for i := 0; i < len(runes); i++ {
+		r := unicode.ToUpper(runes[i])
+
+		switch {
+		case r == 'C' && i+1 < len(runes) && unicode.ToUpper(runes[i+1]) == 'H':
+			sb.WriteRune('Q')
+			i++
+		case r >= 'A' && r <= 'Z':
+			sb.WriteRune(r)
+		case r == 'Ä':
+			sb.WriteString("AE")
+		case r == 'Ö':
+			sb.WriteString("OE")
+		case r == 'Ü':
+			sb.WriteString("UE")
+		case r == 'ß':
+			sb.WriteString("SS")
+		case r >= '0' && r <= '9':
+			j := i
+			for j < len(runes) && runes[j] >= '0' && runes[j] <= '9' {
+				j++
+			}
+
+			sb.WriteString(c.writeDigits(runes[i:j]))
+			i = j - 1
+		default:
+			if s, ok := c.punctuation[r]; ok {
+				sb.WriteString(s)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func (c *conventionImpl) writeDigits(digits []rune) string {
+	var sb strings.Builder
+
+	if c.digits == digitsTopRow {
+		sb.WriteRune('Y')
+	}
+
+	for _, d := range digits {
+		if c.digits == digitsTopRow {
+			sb.WriteByte(topRow[(d-'0'+9)%10])
+		} else {
+			sb.WriteString(spelledDigits[d-'0'])
+		}
+	}
+
+	if c.digits == digitsTopRow {
+		sb.WriteRune('Y')
+	}
+
+	return sb.String()
+}
+
+func (c *conventionImpl) Restore(message string) string {
+	var sb strings.Builder
+	tokens := c.restoreTokens()
+	letters := Letters(message)
+
+	for i := 0; i < len(letters); {
+		if c.digits == digitsTopRow {
+			if n, s := restoreTopRow(letters[i:]); n > 0 {
+				sb.WriteString(s)
+				i += n
+				continue
+			}
+		}
+
+		found := false
+		for _, t := range tokens {
+			if strings.HasPrefix(letters[i:], t.from) {
+				sb.WriteString(t.to)
+				i += len(t.from)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			if letters[i] == 'Q' {
+				sb.WriteString("CH")
+			} else {
+				sb.WriteByte(letters[i])
+			}
+			i++
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+type restoreToken struct {
+	from string
+	to   string
+}
+
+// restoreTokens returns the punctuation rules in reverse, with the longest ones first
+func (c *conventionImpl) restoreTokens() []restoreToken {
+	tokens := make([]restoreToken, 0, len(c.punctuation))
+
+	for r, s := range c.punctuation {
+		to := string(r)
+		if r == '.' || r == ',' || r == ':' || r == '?' {
+			to += " "
+		}
+
+		tokens = append(tokens, restoreToken{s, to})
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if len(tokens[i].from) != len(tokens[j].from) {
+			return len(tokens[i].from) > len(tokens[j].from)
+		}
+
+		return tokens[i].from < tokens[j].from
+	})
+
+	return tokens
+}
+
+// restoreTopRow checks if s starts with a block of digits written with the keyboard top row (ex: 'YQOEOY' is '1939').
+// Returns the number of bytes consumed and the digits, or zero if s does not start with such a block.
+func restoreTopRow(s string) (int, string) {
+	if len(s) < 3 || s[0] != 'Y' {
+		return 0, ""
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] == 'Y' {
+			if i == 1 {
+				return 0, ""
+			}
+
+			return i + 1, sb.String()
+		}
+
+		p := strings.IndexByte(topRow, s[i])
+		if p == -1 {
+			return 0, ""
+		}
+
+		sb.WriteByte(byte('0' + (p+1)%10))
+	}
+
+	return 0, ""
+}
+
+// Letters returns only the letters of message, in uppercase.
+func Letters(message string) string {
+	var sb strings.Builder
+
+	for _, r := range strings.ToUpper(message) {
+		if r >= 'A' && r <= 'Z' {
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,59 @@
+package text_test
+
+import (
+	"testing"
+
+	"github.com/ibraimgm/enigma/text"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConventionNames(t *testing.T) {
+	for name, c := range text.Conventions {
+		assert.Equal(t, name, c.Name())
+	}
+}
+
+func TestConventionPrepare(t *testing.T) {
+	var tests = []struct {
+		convention string
+		original   string
+		expected   string
+	}{
+		{"heer", "Angriff um 5 Uhr.", "ANGRIFFUMFUENFUHRX"},
+		{"heer", "5 oder fünf", "FUENFODERFUENF"},
+		{"heer", "Nächste Brücke, größer?", "NAEQSTEBRUECKEZZGROESSERFRAQ"},
+		{"heer", "Ziel: 1939", "ZIELXXEINSNEUNDREINEUN"},
+		{"heer-y", "Ziel: 1939", "ZIELXXYQOEOY"},
+		{"heer-y", "Zug 20-4.", "ZUGYWPYYYYRYX"},
+		{"kriegsmarine", "U-Boot, 3 Uhr?", "UYYBOOTYDREIUHRUD"},
+		{"kriegsmarine", "Nacht", "NAQT"},
+	}
+
+	for _, test := range tests {
+		actual := text.Conventions[test.convention].Prepare(test.original)
+		assert.Equal(t, test.expected, actual)
+	}
+}
+
+func TestConventionRestore(t *testing.T) {
+	var tests = []struct {
+		convention string
+		decoded    string
+		expected   string
+	}{
+		{"heer", "ANGRI FFUMF UENFU HRX", "ANGRIFFUMFUENFUHR."},
+		{"heer", "NAEQSTEBRUECKEZZGROESSERFRAQ", "NAECHSTEBRUECKE, GROESSER?"},
+		{"heer-y", "ZIELXXYQOEOY", "ZIEL: 1939"},
+		{"kriegsmarine", "UYYBOOTYDREIUHRUD", "U-BOOT, DREIUHR?"},
+	}
+
+	for _, test := range tests {
+		actual := text.Conventions[test.convention].Restore(test.decoded)
+		assert.Equal(t, test.expected, actual)
+	}
+}
+
+func TestLetters(t *testing.T) {
+	assert.Equal(t, "HELLOWORLD", text.Letters("Hello, World! 123"))
+	assert.Equal(t, "FNFBER", text.Letters("fünf über"))
+}
@@ -0,0 +1,174 @@
+package text
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Padding controls how the last group of a formatted message is completed.
+type Padding int
+
+const (
+	// NoPadding leaves the last group incomplete.
+	NoPadding Padding = iota
+	// PadWithX completes the last group with 'X' characters.
+	PadWithX
+	// PadWithNulls completes the last group with random letters ("nulls").
+	PadWithNulls
+)
+
+// Header is the traditional radiogram header, written before the message groups.
+// For example, "1230 - 2tle - 1tl - 180 - WZE HPN -" means a message sent at 12:30, split in 2 parts ("Teile"), being
+// this the first part ("Teil"), with 180 letters and "WZE HPN" as indicator.
+type Header struct {
+	// Time of the message, usually as HHMM.
+	Time string
+	// Parts is the total number of parts of the message. Zero is the same as 1.
+	Parts int
+	// Part is the number of this part, starting with 1. Zero is the same as 1.
+	Part int
+	// Indicator is written in the header as is. Can be empty.
+	Indicator string
+}
+
+// Formatter formats an encoded message to look like the historical signal forms.
+// Every character that is not an uppercase letter is discarded before formatting.
+type Formatter struct {
+	// GroupSize is the number of letters of each group. Zero means 5.
+	GroupSize uint
+	// GroupsPerLine is the number of groups in each line. Zero means all groups in a single line.
+	GroupsPerLine uint
+	// Padding controls how Pad completes the last group.
+	Padding Padding
+	// Nulls is the random source of the null letters, when using PadWithNulls. If nil, a new source is created.
+	Nulls *rand.Rand
+	// NumberLines writes the line number before each line.
+	NumberLines bool
+	// Header, when not nil, is written before the groups. The letter count is calculated by the formatter.
+	Header *Header
+}
+
+// Pad returns the letters of the message (see Letters), completed with the padding so they fill the last group.
+// The padding is part of the plaintext: pad a message before encoding it, so the padding letters are encoded too.
+func (f *Formatter) Pad(message string) string {
+	return f.pad(Letters(message))
+}
+
+// Format returns the formatted message, with one line per group of lines and a line break at the end of each line.
+// The message is not padded (see Pad).
+func (f *Formatter) Format(message string) string {
+	var sb strings.Builder
+
+	letters := onlyUppercase(message)
+	groups := f.groups(letters)
+
+	if f.Header != nil {
+		sb.WriteString(f.Header.format(len(letters)))
+		sb.WriteString("\n")
+	}
+
+	perLine := int(f.GroupsPerLine)
+	if perLine == 0 {
+		// all groups in a single line; the extra group avoids a division by zero on empty messages
+		perLine = len(groups) + 1
+	}
+
+	lines := (len(groups) + perLine - 1) / perLine
+	width := len(fmt.Sprint(lines))
+
+	for i := 0; i < lines; i++ {
+		end := (i + 1) * perLine
+		if end > len(groups) {
+			end = len(groups)
+		}
+
+		if f.NumberLines {
+			fmt.Fprintf(&sb, "%0*d  ", width, i+1)
+		}
+
+		sb.WriteString(strings.Join(groups[i*perLine:end], " "))
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func (f *Formatter) groupSize() int {
+	if f.GroupSize == 0 {
+		return 5
+	}
+
+	return int(f.GroupSize)
+}
+
+func (f *Formatter) pad(letters string) string {
+	size := f.groupSize()
+	missing := (size - len(letters)%size) % size
+
+	if missing == 0 || f.Padding == NoPadding {
+		return letters
+	}
+
+	if f.Padding == PadWithX {
+		return letters + strings.Repeat("X", missing)
+	}
+
+	rng := f.Nulls
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	nulls := make([]byte, missing)
+	for i := range nulls {
+		nulls[i] = byte('A' + rng.Intn(26))
+	}
+
+	return letters + string(nulls)
+}
+
+func (f *Formatter) groups(letters string) []string {
+	size := f.groupSize()
+	groups := make([]string, 0, (len(letters)+size-1)/size)
+
+	for i := 0; i < len(letters); i += size {
+		end := i + size
+		if end > len(letters) {
+			end = len(letters)
+		}
+
+		groups = append(groups, letters[i:end])
+	}
+
+	return groups
+}
+
+func (h *Header) format(count int) string {
+	parts, part := h.Parts, h.Part
+	if parts == 0 {
+		parts = 1
+	}
+	if part == 0 {
+		part = 1
+	}
+
+	s := fmt.Sprintf("%s - %dtle - %dtl - %d -", h.Time, parts, part, count)
+	if h.Indicator != "" {
+		s += " " + h.Indicator + " -"
+	}
+
+	return s
+}
+
+func onlyUppercase(message string) string {
+	var sb strings.Builder
+
+	for _, r := range message {
+		if r >= 'A' && r <= 'Z' {
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,71 @@
+package text_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ibraimgm/enigma/text"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatterGroups(t *testing.T) {
+	var tests = []struct {
+		formatter text.Formatter
+		message   string
+		expected  string
+	}{
+		{text.Formatter{}, "VWJBFIGXKXEOS", "VWJBF IGXKX EOS\n"},
+		{text.Formatter{GroupSize: 4}, "VWJB FIGX KXEOS", "VWJB FIGX KXEO S\n"},
+		{text.Formatter{GroupsPerLine: 2}, "VWJBFIGXKXEOS", "VWJBF IGXKX\nEOS\n"},
+		{text.Formatter{Padding: text.PadWithX}, "VWJBFIGXKXEOS", "VWJBF IGXKX EOS\n"},
+		{text.Formatter{GroupSize: 3, GroupsPerLine: 1, NumberLines: true}, "VWJBFIGXKXEOS", "1  VWJ\n2  BFI\n3  GXK\n4  XEO\n5  S\n"},
+		{text.Formatter{GroupSize: 1, GroupsPerLine: 1, NumberLines: true}, "VWJBFIGXKXEOS", "01  V\n02  W\n03  J\n04  B\n05  F\n06  I\n07  G\n08  X\n09  K\n10  X\n11  E\n12  O\n13  S\n"},
+		{text.Formatter{}, "", ""},
+	}
+
+	for _, test := range tests {
+		actual := test.formatter.Format(test.message)
+		assert.Equal(t, test.expected, actual)
+	}
+}
+
+func TestFormatterPad(t *testing.T) {
+	var tests = []struct {
+		formatter text.Formatter
+		message   string
+		expected  string
+	}{
+		{text.Formatter{}, "Enigma machine", "ENIGMAMACHINE"},
+		{text.Formatter{Padding: text.PadWithX}, "Enigma machine", "ENIGMAMACHINEXX"},
+		{text.Formatter{Padding: text.PadWithX, GroupSize: 4}, "Enigma machine", "ENIGMAMACHINEXXX"},
+		{text.Formatter{Padding: text.PadWithX}, "Enigma", "ENIGMAXXXX"},
+		{text.Formatter{Padding: text.PadWithX}, "", ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.formatter.Pad(test.message))
+	}
+}
+
+func TestFormatterNulls(t *testing.T) {
+	f := text.Formatter{Padding: text.PadWithNulls, Nulls: rand.New(rand.NewSource(1))}
+	actual := f.Pad("ENIGMAMACHINE")
+
+	assert.Len(t, actual, len("ENIGMAMACHINEXX"))
+	assert.Regexp(t, "^ENIGMAMACHINE[A-Z]{2}$", actual)
+}
+
+func TestFormatterHeader(t *testing.T) {
+	f := text.Formatter{
+		GroupsPerLine: 2,
+		Padding:       text.PadWithX,
+		Header:        &text.Header{Time: "1230", Parts: 2, Part: 1, Indicator: "WZE HPN"},
+	}
+
+	actual := f.Format(f.Pad("VWJBFIGXKXEOS"))
+	assert.Equal(t, "1230 - 2tle - 1tl - 15 - WZE HPN -\nVWJBF IGXKX\nEOSXX\n", actual)
+
+	f.Header = &text.Header{Time: "0915"}
+	actual = f.Format("VWJBF")
+	assert.Equal(t, "0915 - 1tle - 1tl - 5 -\nVWJBF\n", actual)
+}